@@ -301,6 +301,23 @@ function setupLazyImages() {
     });
 }
 
+// images that failed to load are removed so that the placeholder after them shows instead
+// of a broken image, the ones that failed before the page was set up are complete but empty
+function setupImagePlaceholders() {
+    const images = document.querySelectorAll("img[data-placeholder-on-error]");
+
+    for (let i = 0; i < images.length; i++) {
+        const image = images[i];
+
+        if (image.complete && image.naturalWidth == 0) {
+            image.remove();
+            continue;
+        }
+
+        image.addEventListener("error", () => image.remove());
+    }
+}
+
 function attachExpandToggleButton(collapsibleContainer) {
     const showMoreText = "Show more";
     const showLessText = "Show less";
@@ -454,6 +471,37 @@ function setupCollapsibleGrids() {
     }
 }
 
+// the endpoint only updates the widget, the page is reloaded afterwards rather than
+// swapping in the new content since everything else is only set up once for the whole page
+function setupWidgetRefreshButtons() {
+    const buttons = document.getElementsByClassName("widget-refresh-button");
+
+    for (let i = 0; i < buttons.length; i++) {
+        const button = buttons[i];
+
+        button.addEventListener("click", async () => {
+            if (button.classList.contains("refreshing")) {
+                return;
+            }
+
+            button.classList.add("refreshing");
+
+            try {
+                const response = await fetch(`${pageData.baseURL}/api/widgets/${button.dataset.widgetId}/refresh`, { method: "POST" });
+
+                if (response.ok) {
+                    location.reload();
+                    return;
+                }
+            } catch (e) {
+                console.error(e);
+            }
+
+            button.classList.remove("refreshing");
+        });
+    }
+}
+
 const contentReadyCallbacks = [];
 
 function afterContentReady(callback) {
@@ -583,6 +631,8 @@ async function setupPage() {
         setupGroups();
         setupDynamicRelativeTime();
         setupLazyImages();
+        setupImagePlaceholders();
+        setupWidgetRefreshButtons();
     } finally {
         pageElement.classList.add("content-ready");
 
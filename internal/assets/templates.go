@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"html/template"
 	"math"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/text/language"
@@ -31,6 +34,9 @@ var (
 	RSSDetailedListTemplate       = compileTemplate("rss-detailed-list.html", "widget-base.html")
 	RSSHorizontalCardsTemplate    = compileTemplate("rss-horizontal-cards.html", "widget-base.html")
 	RSSHorizontalCards2Template   = compileTemplate("rss-horizontal-cards-2.html", "widget-base.html")
+	FreshRSSListTemplate          = compileTemplate("freshrss-list.html", "widget-base.html")
+	FreshRSSDetailedListTemplate  = compileTemplate("freshrss-detailed-list.html", "widget-base.html")
+	FreshRSSUnreadTemplate        = compileTemplate("freshrss-unread.html", "widget-base.html")
 	MonitorTemplate               = compileTemplate("monitor.html", "widget-base.html")
 	TwitchGamesListTemplate       = compileTemplate("twitch-games-list.html", "widget-base.html")
 	TwitchChannelsTemplate        = compileTemplate("twitch-channels.html", "widget-base.html")
@@ -39,12 +45,25 @@ var (
 	ExtensionTemplate             = compileTemplate("extension.html", "widget-base.html")
 	GroupTemplate                 = compileTemplate("group.html", "widget-base.html")
 	DNSStatsTemplate              = compileTemplate("dns-stats.html", "widget-base.html")
+	ArrReleasesTemplate           = compileTemplate("arr-releases.html", "widget-base.html", "arr-poster.html")
+	SonarrPremieresTemplate       = compileTemplate("sonarr-premieres.html", "widget-base.html", "arr-poster.html")
+	SonarrStatsTemplate           = compileTemplate("sonarr-stats.html", "widget-base.html")
+	SonarrCutoffUnmetTemplate     = compileTemplate("sonarr-cutoff-unmet.html", "widget-base.html", "arr-poster.html")
+	RadarrCollectionsTemplate     = compileTemplate("radarr-collections.html", "widget-base.html", "arr-poster.html")
+	JellyfinRecentlyAddedTemplate = compileTemplate("jellyfin-recently-added.html", "widget-base.html")
+	TautulliTemplate              = compileTemplate("tautulli.html", "widget-base.html")
+	DownloadClientTemplate        = compileTemplate("download-client.html", "widget-base.html")
+	JSONAPITemplate               = compileTemplate("json-api.html", "widget-base.html")
 )
 
 var globalTemplateFunctions = template.FuncMap{
 	"relativeTime":      relativeTimeSince,
 	"formatViewerCount": formatViewerCount,
 	"formatNumber":      intl.Sprint,
+	"formatBytes":       formatBytes,
+	"formatDuration":    formatDuration,
+	"humanBytes":        humanBytes,
+	"humanDuration":     humanDuration,
 	"absInt": func(i int) int {
 		return int(math.Abs(float64(i)))
 	},
@@ -56,6 +75,31 @@ var globalTemplateFunctions = template.FuncMap{
 	},
 }
 
+// for templates that are specified in the config, with the same functions available
+func CompileUserTemplate(text string) (*template.Template, error) {
+	return template.New("").Funcs(globalTemplateFunctions).Parse(text)
+}
+
+// for templates that replace those of widgets, they're parsed along with the ones that the
+// built-in templates depend on so that they can be based on a copy of one of them
+func CompileUserTemplateFile(path string) (*template.Template, error) {
+	contents, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := template.New(filepath.Base(path)).
+		Funcs(globalTemplateFunctions).
+		ParseFS(TemplateFS, "widget-base.html", "arr-poster.html")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t.Parse(string(contents))
+}
+
 func compileTemplate(primary string, dependencies ...string) *template.Template {
 	t, err := template.New(primary).
 		Funcs(globalTemplateFunctions).
@@ -86,6 +130,128 @@ func formatViewerCount(count int) string {
 	return fmt.Sprintf("%.1fm", float64(count)/1_000_000)
 }
 
+func formatBytes(bytes int64) string {
+	const unit = 1024
+
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	value := float64(bytes) / unit
+	units := []string{"KiB", "MiB", "GiB", "TiB", "PiB"}
+	i := 0
+
+	for value >= unit && i < len(units)-1 {
+		value /= unit
+		i++
+	}
+
+	return fmt.Sprintf("%.1f %s", value, units[i])
+}
+
+// numbers in templates come in different types depending on where they're from,
+// e.g. JSON numbers are always float64, so the human* functions accept any of them
+func toFloat64(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+
+	return 0, false
+}
+
+// uses powers of 1000 like most file managers rather than 1024 like formatBytes, e.g. 2.1 GB
+func humanBytes(value any) string {
+	bytes, ok := toFloat64(value)
+
+	if !ok {
+		return fmt.Sprint(value)
+	}
+
+	if math.Abs(bytes) < 1000 {
+		return fmt.Sprintf("%d B", int64(bytes))
+	}
+
+	units := []string{"kB", "MB", "GB", "TB", "PB"}
+	i := 0
+	bytes /= 1000
+
+	for math.Abs(bytes) >= 1000 && i < len(units)-1 {
+		bytes /= 1000
+		i++
+	}
+
+	return fmt.Sprintf("%.1f %s", bytes, units[i])
+}
+
+// takes a number of minutes, which is how runtimes are usually given, e.g. 45m or 2h 10m
+func humanDuration(value any) string {
+	minutes, ok := toFloat64(value)
+
+	if !ok {
+		return fmt.Sprint(value)
+	}
+
+	d := time.Duration(minutes * float64(time.Minute)).Round(time.Minute)
+	hours, remaining := d/time.Hour, (d%time.Hour)/time.Minute
+
+	if hours == 0 {
+		return fmt.Sprintf("%dm", remaining)
+	}
+
+	if remaining == 0 {
+		return fmt.Sprintf("%dh", hours)
+	}
+
+	return fmt.Sprintf("%dh %dm", hours, remaining)
+}
+
+// shows at most the two largest units, e.g. 2d 3h or 4m 5s
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+
+	units := []struct {
+		size   time.Duration
+		suffix string
+	}{
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+		{time.Second, "s"},
+	}
+
+	parts := make([]string, 0, 2)
+
+	for _, unit := range units {
+		if d < unit.size && len(parts) == 0 {
+			continue
+		}
+
+		parts = append(parts, fmt.Sprintf("%d%s", d/unit.size, unit.suffix))
+		d %= unit.size
+
+		if len(parts) == 2 {
+			break
+		}
+	}
+
+	if len(parts) == 0 {
+		return "0s"
+	}
+
+	return strings.Join(parts, " ")
+}
+
 func relativeTimeSince(t time.Time) string {
 	delta := time.Since(t)
 
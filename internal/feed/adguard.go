@@ -2,7 +2,6 @@ package feed
 
 import (
 	"net/http"
-	"strings"
 )
 
 type adguardStatsResponse struct {
@@ -15,7 +14,7 @@ type adguardStatsResponse struct {
 }
 
 func FetchAdguardStats(instanceURL, username, password string) (*DNSStats, error) {
-	requestURL := strings.TrimRight(instanceURL, "/") + "/control/stats"
+	requestURL := joinURL(instanceURL, "control/stats")
 
 	request, err := http.NewRequest("GET", requestURL, nil)
 
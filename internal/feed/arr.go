@@ -0,0 +1,730 @@
+package feed
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ArrSource string
+
+const (
+	ArrSourceSonarr ArrSource = "sonarr"
+	ArrSourceRadarr ArrSource = "radarr"
+	ArrSourceLidarr ArrSource = "lidarr"
+)
+
+type ArrReleaseRequest struct {
+	Source        ArrSource
+	URL           string
+	LinkBase      string
+	APIKey        string
+	AllowInsecure bool
+	UserAgent     string
+	// set on every request made to the instance after the default ones
+	Headers      map[string]string
+	Networks     []string
+	Availability string
+	// shifts the window by this many days, e.g. 1 shows tomorrow's releases
+	DayOffset int
+	// extends the window back by this many days before the offset day
+	FromPreviousDays int
+	// when either is set, the window rolls with the current time instead of being
+	// made up of whole days, covering from this long before now to this long after
+	HoursBehind    time.Duration
+	HoursAhead     time.Duration
+	OverviewLength int
+	// when set, poster URLs are rewritten to go through it, see posterURL
+	PosterURLTemplate string
+	// either utc or network, see sonarrNetworkAirDate
+	AirDateSource string
+	// the hour at which days start rather than at midnight, so that e.g. an episode
+	// airing at 1am still counts towards the previous day when set to 4
+	DayStartHour int
+	// when not empty, only releases falling on these days of the week are kept
+	Weekdays []time.Weekday
+	// added to calendar requests, replacing any of the parameters set by default
+	ExtraQuery map[string]string
+	// whether the episodes of a season that all air on the same day are shown as one
+	// release, see collapseSeasonPacks
+	CollapseSeasons bool
+	// the path that the instance is served under, discovered from the instance when
+	// empty, see baseURL
+	URLBase string
+	// either poster, fanart or banner, see findImageURL
+	ImageType string
+	// either series or episode, the latter uses the stills of Sonarr episodes when
+	// they have one and the image of the series otherwise
+	ImageSource string
+	// the width of the images to request from hosts that have resized versions
+	// of them, 0 leaves them as they are, see resizeArrImageURL
+	ThumbnailSize int
+	// one of the keys of ArrEpisodeFormats, see episodeCode
+	EpisodeFormat string
+}
+
+type ArrRelease struct {
+	Source        ArrSource
+	SourceIconURL string
+	Title         string
+	Subtitle      string
+	Overview      string
+	URL           string
+	ImageURL      string
+	ReleaseType   string
+	// Series, Season or Midseason Finale for episodes that end one, empty otherwise
+	Finale        string
+	SeasonNumber  int
+	EpisodeNumber int
+	// the season and episode numbers in the configured format, e.g. S01E02
+	EpisodeCode string
+	// the number of episodes in a collapsed season, 0 for single episodes
+	EpisodeCount  int
+	Network       string
+	SeriesType    string
+	Runtime       int
+	Certification string
+	Status        string
+	IMDbURL       string
+	TMDbURL       string
+	ReleasedAt    time.Time
+	// Yesterday, Today or Tomorrow relative to the day the releases were fetched on, empty otherwise
+	RelativeDay string
+	Grabbed     bool
+	// whether the episode has been played on the media server, see JellyfinPlayedEpisodes
+	Watched bool
+	// the TheTVDB IDs of Sonarr episodes and their series, 0 for everything else
+	TVDbID       int
+	SeriesTVDbID int
+	// quality and size of the downloaded file, empty when it hasn't been grabbed
+	Quality  string
+	FileSize int64
+}
+
+type ArrReleases []ArrRelease
+
+// releases airing at the same time are ordered by title and then by episode, since
+// the order they're returned in can change between requests and the list would
+// otherwise get reshuffled on every update
+func (r ArrReleases) SortByReleaseTime() ArrReleases {
+	slices.SortStableFunc(r, func(a, b ArrRelease) int {
+		return cmp.Or(
+			a.ReleasedAt.Compare(b.ReleasedAt),
+			strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)),
+			cmp.Compare(a.SeasonNumber, b.SeasonNumber),
+			cmp.Compare(a.EpisodeNumber, b.EpisodeNumber),
+			strings.Compare(a.ReleaseType, b.ReleaseType),
+			strings.Compare(string(a.Source), string(b.Source)),
+		)
+	})
+
+	return r
+}
+
+// the same structure is used by both Sonarr and Radarr
+type arrMediaFile struct {
+	Size      int64  `json:"size"`
+	DateAdded string `json:"dateAdded"`
+	Quality   struct {
+		Quality struct {
+			Name string `json:"name"`
+		} `json:"quality"`
+	} `json:"quality"`
+}
+
+type arrImage struct {
+	CoverType string `json:"coverType"`
+	RemoteURL string `json:"remoteUrl"`
+}
+
+// newly added series and movies can have no images yet, or images that only have a
+// path on the instance itself until they've been fetched, both count as not having one
+func findArrImageURL(images []arrImage, coverType string) string {
+	for i := range images {
+		if images[i].CoverType == coverType && images[i].RemoteURL != "" {
+			return images[i].RemoteURL
+		}
+	}
+
+	return ""
+}
+
+// the image of the configured type, falling back to the poster when there
+// isn't one since every series and movie has at least that
+func (request *ArrReleaseRequest) findImageURL(images []arrImage) string {
+	if request.ImageType != "" && request.ImageType != "poster" {
+		if imageURL := findArrImageURL(images, request.ImageType); imageURL != "" {
+			return imageURL
+		}
+	}
+
+	return findArrImageURL(images, "poster")
+}
+
+// keyed by how the second episode of the first season looks in each of them
+var ArrEpisodeFormats = map[string]string{
+	"S01E02": "S%02[1]dE%02[2]d",
+	"1x02":   "%[1]dx%02[2]d",
+	"E02":    "E%02[2]d",
+}
+
+func (request *ArrReleaseRequest) episodeCode(season, episode int) string {
+	format, exists := ArrEpisodeFormats[request.EpisodeFormat]
+
+	if !exists {
+		format = ArrEpisodeFormats["S01E02"]
+	}
+
+	return fmt.Sprintf(format, season, episode)
+}
+
+// the widths that TMDb serves images at other than the original
+var ArrThumbnailSizes = []int{185, 342, 500, 780}
+
+// the *arr apps return the original images, which can be several thousand pixels wide.
+// TMDb, where Radarr and some of Sonarr's images come from, can resize them through
+// the size in the path while TheTVDB only has a single smaller version of each image
+func resizeArrImageURL(imageURL string, size int) string {
+	if size == 0 {
+		return imageURL
+	}
+
+	parsed, err := url.Parse(imageURL)
+
+	if err != nil {
+		return imageURL
+	}
+
+	switch parsed.Host {
+	case "image.tmdb.org":
+		// e.g. /t/p/original/abc.jpg
+		segments := strings.SplitN(parsed.Path, "/", 5)
+
+		if len(segments) == 5 && segments[1] == "t" && segments[2] == "p" {
+			segments[3] = "w" + strconv.Itoa(size)
+			parsed.Path = strings.Join(segments, "/")
+		}
+	case "artworks.thetvdb.com":
+		// thumbnails are around 340 pixels wide, so they're only used for the smaller sizes
+		if size > 342 || strings.Contains(parsed.Path, "/_cache/") || strings.Contains(parsed.Path, "_t.") {
+			return imageURL
+		}
+
+		if strings.HasPrefix(parsed.Path, "/banners/v4/") {
+			ext := path.Ext(parsed.Path)
+			parsed.Path = strings.TrimSuffix(parsed.Path, ext) + "_t" + ext
+		} else if rest, found := strings.CutPrefix(parsed.Path, "/banners/"); found {
+			parsed.Path = "/banners/_cache/" + rest
+		}
+	}
+
+	return parsed.String()
+}
+
+// {remoteUrl} is replaced as is for services that take the URL as part of the path,
+// {remoteUrlEncoded} is query escaped for services that take it as a parameter
+func (request *ArrReleaseRequest) posterURL(remoteURL string) string {
+	remoteURL = resizeArrImageURL(remoteURL, request.ThumbnailSize)
+
+	if request.PosterURLTemplate == "" || remoteURL == "" {
+		return remoteURL
+	}
+
+	return strings.NewReplacer(
+		"{remoteUrl}", remoteURL,
+		"{remoteUrlEncoded}", url.QueryEscape(remoteURL),
+	).Replace(request.PosterURLTemplate)
+}
+
+// links can point to a different host than the one the API is queried through,
+// e.g. a public domain while the API is reached over the local network
+func (request *ArrReleaseRequest) linkTo(path string) string {
+	base := request.LinkBase
+
+	// the url base has always been discovered by the time links are built
+	if base == "" {
+		base = request.baseURL(context.Background())
+	}
+
+	return joinURL(base, path)
+}
+
+// instances served under a url base redirect requests made without it, so they
+// work either way, but each of them costs an extra round trip and links to the
+// instance would point to the wrong place until followed
+func (request *ArrReleaseRequest) baseURL(ctx context.Context) string {
+	urlBase := request.URLBase
+
+	if urlBase == "" {
+		urlBase = discoverArrURLBase(ctx, request)
+	}
+
+	return joinArrURLBase(request.URL, urlBase)
+}
+
+func joinArrURLBase(instanceURL, urlBase string) string {
+	instanceURL = strings.TrimRight(instanceURL, "/")
+	urlBase = strings.Trim(urlBase, "/")
+
+	if urlBase == "" {
+		return instanceURL
+	}
+
+	// the url already includes it
+	if parsed, err := url.Parse(instanceURL); err == nil && strings.HasSuffix(strings.TrimRight(parsed.Path, "/"), "/"+urlBase) {
+		return instanceURL
+	}
+
+	return joinURL(instanceURL, urlBase)
+}
+
+const (
+	discoveredArrURLBaseCacheDuration = 24 * time.Hour
+	// an instance that couldn't be reached is tried again sooner
+	failedArrURLBaseCacheDuration = 10 * time.Minute
+)
+
+type discoveredArrURLBase struct {
+	urlBase   string
+	expiresAt time.Time
+}
+
+var discoveredArrURLBases = make(map[string]discoveredArrURLBase)
+var discoveredArrURLBasesMutex sync.Mutex
+
+func discoverArrURLBase(ctx context.Context, request *ArrReleaseRequest) string {
+	discoveredArrURLBasesMutex.Lock()
+	cached, exists := discoveredArrURLBases[request.URL]
+	discoveredArrURLBasesMutex.Unlock()
+
+	if exists && time.Now().Before(cached.expiresAt) {
+		return cached.urlBase
+	}
+
+	discovered := discoveredArrURLBase{expiresAt: time.Now().Add(discoveredArrURLBaseCacheDuration)}
+	status, err := queryArrApiAt[arrSystemStatusResponseJson](ctx, request, request.URL, arrSystemStatusPath(request.Source), nil)
+
+	if err != nil {
+		discovered.expiresAt = time.Now().Add(failedArrURLBaseCacheDuration)
+	} else {
+		discovered.urlBase = status.URLBase
+	}
+
+	discoveredArrURLBasesMutex.Lock()
+	discoveredArrURLBases[request.URL] = discovered
+	discoveredArrURLBasesMutex.Unlock()
+
+	return discovered.urlBase
+}
+
+func (request *ArrReleaseRequest) withExtraQuery(query url.Values) url.Values {
+	for key, value := range request.ExtraQuery {
+		query.Set(key, value)
+	}
+
+	return query
+}
+
+func (request *ArrReleaseRequest) filterWeekdays(releases ArrReleases) ArrReleases {
+	if len(request.Weekdays) == 0 {
+		return releases
+	}
+
+	return slices.DeleteFunc(releases, func(release ArrRelease) bool {
+		return !slices.Contains(request.Weekdays, getDayStartingAt(release.ReleasedAt, request.DayStartHour).Weekday())
+	})
+}
+
+func (request *ArrReleaseRequest) shortenOverview(overview string) string {
+	if request.OverviewLength < 0 {
+		return ""
+	}
+
+	return limitStringLengthAtWordBoundary(strings.TrimSpace(overview), request.OverviewLength)
+}
+
+func getStartOfDay(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+}
+
+// returns the start of the following day rather than 23:59:59 so that the window is exclusive
+// of its end, days around DST transitions are 23 or 25 hours long so the end is always
+// recomputed in the location instead of being derived by adding hours to the start
+func getEndOfDay(t time.Time, location *time.Location) time.Time {
+	t = t.In(location)
+
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+}
+
+func isWithinWindow(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+// releases that only have a date are kept if any part of their day is within the window,
+// which for windows made up of whole days is the same as the day's start being within it
+// but also keeps today's releases in a rolling window that starts partway through today
+func isDayWithinWindow(day, start, end time.Time) bool {
+	return day.Before(end) && day.AddDate(0, 0, 1).After(start)
+}
+
+// release dates for movies and albums are calendar dates stored as midnight UTC, converting
+// them to the window's location as-is could move them to the previous day. they're placed
+// at the hour days start at so that they still fall on their day when days don't start
+// at midnight
+func parseArrReleaseDate(date string, location *time.Location, dayStartHour int) (time.Time, bool) {
+	if date == "" {
+		return time.Time{}, false
+	}
+
+	parsed, err := time.Parse(time.RFC3339, date)
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), dayStartHour, 0, 0, 0, location), true
+}
+
+// the *arr calendar endpoints filter by UTC dates, so the queried range is padded
+// by a day on each side and the results are then filtered against the actual window
+func arrCalendarQuery(start, end time.Time) url.Values {
+	location := start.Location()
+	paddedStart := time.Date(start.Year(), start.Month(), start.Day()-1, 0, 0, 0, 0, location)
+	paddedEnd := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, location)
+
+	query := url.Values{}
+	query.Set("start", paddedStart.UTC().Format(time.RFC3339))
+	query.Set("end", paddedEnd.UTC().Format(time.RFC3339))
+
+	return query
+}
+
+// several widgets can point at the same instance and their caches tend to expire at
+// the same time, so the number of requests in flight to a single host is limited
+const arrMaxConcurrentRequestsPerHost = 2
+
+var arrHostSlots = make(map[string]chan struct{})
+var arrHostSlotsMutex sync.Mutex
+
+func acquireArrHostSlot(ctx context.Context, host string) (func(), error) {
+	arrHostSlotsMutex.Lock()
+	slots, exists := arrHostSlots[host]
+
+	if !exists {
+		slots = make(chan struct{}, arrMaxConcurrentRequestsPerHost)
+		arrHostSlots[host] = slots
+	}
+
+	arrHostSlotsMutex.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func queryArrApi[T any](ctx context.Context, request *ArrReleaseRequest, path string, query url.Values) (T, error) {
+	return queryArrApiAt[T](ctx, request, request.baseURL(ctx), path, query)
+}
+
+// the calendar endpoints have nothing to return for empty ranges, which some setups
+// respond to with an empty body rather than an empty list
+func queryArrCalendarApi[T any](ctx context.Context, request *ArrReleaseRequest, path string, query url.Values) (T, error) {
+	return doArrApiRequest(ctx, request, request.baseURL(ctx), path, query, decodeJsonOrEmptyFromRequest[T])
+}
+
+func queryArrApiAt[T any](ctx context.Context, request *ArrReleaseRequest, baseURL string, path string, query url.Values) (T, error) {
+	return doArrApiRequest(ctx, request, baseURL, path, query, decodeJsonFromRequest[T])
+}
+
+func doArrApiRequest[T any](ctx context.Context, request *ArrReleaseRequest, baseURL string, path string, query url.Values, decode func(RequestDoer, *http.Request) (T, error)) (T, error) {
+	requestURL := joinURL(baseURL, path)
+
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+
+	if err != nil {
+		var result T
+		return result, err
+	}
+
+	httpRequest.Header.Set("X-Api-Key", request.APIKey)
+
+	if request.UserAgent != "" {
+		httpRequest.Header.Set("User-Agent", request.UserAgent)
+	} else {
+		httpRequest.Header.Set("User-Agent", GlanceUserAgent)
+	}
+
+	setRequestHeaders(httpRequest, request.Headers)
+
+	var client RequestDoer = defaultClient
+
+	if request.AllowInsecure {
+		client = defaultInsecureClient
+	}
+
+	release, err := acquireArrHostSlot(ctx, httpRequest.URL.Host)
+
+	if err != nil {
+		var result T
+		return result, err
+	}
+
+	defer release()
+
+	return decode(client, httpRequest)
+}
+
+const arrPosterMaxSize = 10 << 20
+
+// fetches a poster so that it can be served from glance itself with caching headers,
+// returns the poster along with its content type
+func FetchArrPoster(posterURL string) ([]byte, string, error) {
+	request, err := http.NewRequest("GET", posterURL, nil)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	response, err := defaultClient.Do(request)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code %d for poster %s", response.StatusCode, posterURL)
+	}
+
+	contentType := response.Header.Get("Content-Type")
+
+	if !strings.HasPrefix(contentType, "image/") {
+		return nil, "", fmt.Errorf("unexpected content type '%s' for poster %s", contentType, posterURL)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, arrPosterMaxSize+1))
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(body) > arrPosterMaxSize {
+		return nil, "", fmt.Errorf("poster %s is larger than %d bytes", posterURL, arrPosterMaxSize)
+	}
+
+	return body, contentType, nil
+}
+
+// the envelope returned by paged endpoints such as the queue, history and wanted ones
+type arrPagedResponseJson[T any] struct {
+	Page         int `json:"page"`
+	PageSize     int `json:"pageSize"`
+	TotalRecords int `json:"totalRecords"`
+	Records      []T `json:"records"`
+}
+
+const (
+	arrPageSize = 50
+	// guards against instances that keep reporting more records than they return
+	arrMaxPages = 20
+)
+
+// follows the pages of a paged endpoint until all records have been fetched or
+// maxItems is reached, returns the records alongside the total the instance reported
+func queryArrPagedApi[T any](ctx context.Context, request *ArrReleaseRequest, path string, query url.Values, maxItems int) ([]T, int, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+
+	query.Set("pageSize", strconv.Itoa(arrPageSize))
+
+	var records []T
+	var totalRecords int
+
+	for page := 1; page <= arrMaxPages; page++ {
+		query.Set("page", strconv.Itoa(page))
+
+		response, err := queryArrApi[arrPagedResponseJson[T]](ctx, request, path, query)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		totalRecords = response.TotalRecords
+		records = append(records, response.Records...)
+
+		if maxItems > 0 && len(records) >= maxItems {
+			return records[:maxItems], totalRecords, nil
+		}
+
+		if len(response.Records) == 0 || page*arrPageSize >= totalRecords {
+			break
+		}
+	}
+
+	return records, totalRecords, nil
+}
+
+type arrSystemStatusResponseJson struct {
+	AppName string `json:"appName"`
+	Version string `json:"version"`
+	URLBase string `json:"urlBase"`
+}
+
+func arrSystemStatusPath(source ArrSource) string {
+	if source == ArrSourceLidarr {
+		return "/api/v1/system/status"
+	}
+
+	return "/api/v3/system/status"
+}
+
+// checks that the instance is reachable and that the api key is valid,
+// returns the name and version of the app on success
+func CheckArrConnection(request *ArrReleaseRequest) (string, error) {
+	response, err := queryArrApi[arrSystemStatusResponseJson](context.Background(), request, arrSystemStatusPath(request.Source), nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	return response.AppName + " " + response.Version, nil
+}
+
+func fetchReleasesFromArrTask(ctx context.Context, now time.Time) func(*ArrReleaseRequest) (ArrReleases, error) {
+	return func(request *ArrReleaseRequest) (ArrReleases, error) {
+		start, end := request.window(now)
+
+		var releases ArrReleases
+		var err error
+
+		switch request.Source {
+		case ArrSourceSonarr:
+			releases, err = fetchReleasesFromSonarr(ctx, request, start, end)
+		case ArrSourceRadarr:
+			releases, err = fetchReleasesFromRadarr(ctx, request, start, end)
+		case ArrSourceLidarr:
+			releases, err = fetchReleasesFromLidarr(ctx, request, start, end)
+		default:
+			return nil, errors.New("unsupported source")
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		releases = request.filterWeekdays(releases)
+
+		for i := range releases {
+			releases[i].RelativeDay = relativeDayLabel(now, releases[i].ReleasedAt, request.DayStartHour)
+		}
+
+		return releases, nil
+	}
+}
+
+// the window covers the whole day that now falls on, in now's location
+func getArrReleasesWindow(now time.Time) (time.Time, time.Time) {
+	return getStartOfDay(now, now.Location()), getEndOfDay(now, now.Location())
+}
+
+// the day that t counts towards when days start at the given hour rather than at midnight
+func getDayStartingAt(t time.Time, dayStartHour int) time.Time {
+	if t.Hour() < dayStartHour {
+		return t.AddDate(0, 0, -1)
+	}
+
+	return t
+}
+
+func atHourOfDay(t time.Time, hour int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), hour, 0, 0, 0, t.Location())
+}
+
+func (request *ArrReleaseRequest) window(now time.Time) (time.Time, time.Time) {
+	if request.HoursBehind > 0 || request.HoursAhead > 0 {
+		return now.Add(-request.HoursBehind), now.Add(request.HoursAhead)
+	}
+
+	day := getDayStartingAt(now, request.DayStartHour).AddDate(0, 0, request.DayOffset)
+	start, end := getArrReleasesWindow(day)
+
+	if request.FromPreviousDays > 0 {
+		start = getStartOfDay(day.AddDate(0, 0, -request.FromPreviousDays), day.Location())
+	}
+
+	if request.DayStartHour > 0 {
+		start, end = atHourOfDay(start, request.DayStartHour), atHourOfDay(end, request.DayStartHour)
+	}
+
+	return start, end
+}
+
+func relativeDayLabel(now, t time.Time, dayStartHour int) string {
+	switch daysBetween(getDayStartingAt(now, dayStartHour), getDayStartingAt(t.In(now.Location()), dayStartHour)) {
+	case -1:
+		return "Yesterday"
+	case 0:
+		return "Today"
+	case 1:
+		return "Tomorrow"
+	}
+
+	return ""
+}
+
+func FetchReleasesFromArrStack(ctx context.Context, logger *slog.Logger, requests []*ArrReleaseRequest, now time.Time) (ArrReleases, error) {
+	job := newJob(fetchReleasesFromArrTask(ctx, now), requests).withWorkers(10)
+	results, errs, err := workerPoolDo(job)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []string
+
+	releases := make(ArrReleases, 0, len(requests)*5)
+
+	for i := range results {
+		if errs[i] != nil {
+			failed = append(failed, fmt.Sprintf("%s at %s", requests[i].Source, urlHost(requests[i].URL)))
+			logger.Error("Failed to fetch releases", "source", requests[i].Source, "host", urlHost(requests[i].URL), "error", errs[i])
+			continue
+		}
+
+		releases = append(releases, results[i]...)
+	}
+
+	if len(failed) == len(requests) {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, errs[0])
+	}
+
+	releases.SortByReleaseTime()
+
+	if len(failed) > 0 {
+		return releases, fmt.Errorf("%w: could not reach %s", ErrPartialContent, strings.Join(failed, ", "))
+	}
+
+	return releases, nil
+}
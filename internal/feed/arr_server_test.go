@@ -0,0 +1,1051 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type mockArrServer struct {
+	*httptest.Server
+	status int
+	body   string
+
+	mu   sync.Mutex
+	last mockArrRequest
+}
+
+type mockArrRequest struct {
+	apiKey    string
+	userAgent string
+	path      string
+	query     string
+}
+
+func (server *mockArrServer) lastRequest() mockArrRequest {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	return server.last
+}
+
+// serves the same canned response for every request and records what was requested
+func newMockArrServer(t *testing.T, useTLS bool, status int, body string) *mockArrServer {
+	t.Helper()
+
+	server := &mockArrServer{status: status, body: body}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		server.last = mockArrRequest{
+			apiKey:    r.Header.Get("X-Api-Key"),
+			userAgent: r.Header.Get("User-Agent"),
+			path:      r.URL.Path,
+			query:     r.URL.RawQuery,
+		}
+		server.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(server.status)
+		w.Write([]byte(server.body))
+	})
+
+	if useTLS {
+		server.Server = httptest.NewTLSServer(handler)
+	} else {
+		server.Server = httptest.NewServer(handler)
+	}
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+const mockSonarrCalendarJson = `[
+	{
+		"seasonNumber": 2,
+		"episodeNumber": 5,
+		"title": "The One Today",
+		"overview": "Something happens.",
+		"airDateUtc": "2024-05-10T20:00:00Z",
+		"hasFile": true,
+		"episodeFile": {"size": 2254857830, "quality": {"quality": {"name": "WEBDL-1080p", "resolution": 1080}}},
+		"series": {
+			"title": "Some Show",
+			"titleSlug": "some-show",
+			"network": "HBO",
+			"seriesType": "standard",
+			"images": [{"coverType": "poster", "remoteUrl": "https://example.com/poster.jpg"}]
+		}
+	},
+	{
+		"seasonNumber": 2,
+		"episodeNumber": 6,
+		"title": "The One Tomorrow",
+		"airDateUtc": "2024-05-11T20:00:00Z",
+		"series": {"title": "Some Show", "titleSlug": "some-show", "network": "HBO"}
+	}
+]`
+
+const mockRadarrCalendarJson = `[
+	{
+		"title": "Some Movie",
+		"titleSlug": "some-movie-2024",
+		"overview": "  Something happens to someone.  ",
+		"runtime": 120,
+		"certification": "PG-13",
+		"imdbId": "tt0000001",
+		"tmdbId": 12345,
+		"inCinemas": "2024-03-01T00:00:00Z",
+		"digitalRelease": "2024-05-10T00:00:00Z",
+		"status": "released",
+		"isAvailable": true,
+		"hasFile": false,
+		"images": [{"coverType": "poster", "remoteUrl": "https://example.com/movie.jpg"}]
+	},
+	{
+		"title": "Another Movie",
+		"titleSlug": "another-movie-2024",
+		"inCinemas": "2024-05-12T00:00:00Z"
+	}
+]`
+
+func mockArrWindow() (time.Time, time.Time) {
+	return getArrReleasesWindow(time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC))
+}
+
+func TestFetchReleasesFromSonarr(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, mockSonarrCalendarJson)
+	request := &ArrReleaseRequest{Source: ArrSourceSonarr, URL: server.URL + "/", APIKey: "secret", OverviewLength: 140}
+	start, end := mockArrWindow()
+
+	releases, err := fetchReleasesFromSonarr(context.Background(), request, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	last := server.lastRequest()
+
+	if last.apiKey != "secret" {
+		t.Errorf("expected X-Api-Key header to be secret, got %q", last.apiKey)
+	}
+
+	if last.path != "/api/v3/calendar" {
+		t.Errorf("expected path /api/v3/calendar, got %s", last.path)
+	}
+
+	if !strings.Contains(last.query, "includeSeries=true") || !strings.Contains(last.query, "includeEpisodeFile=true") {
+		t.Errorf("expected includeSeries and includeEpisodeFile in query, got %s", last.query)
+	}
+
+	if len(releases) != 1 {
+		t.Fatalf("expected 1 release, got %d", len(releases))
+	}
+
+	release := releases[0]
+
+	if release.Title != "Some Show" || release.Subtitle != "S02E05 · The One Today" {
+		t.Errorf("unexpected title and subtitle %q / %q", release.Title, release.Subtitle)
+	}
+
+	if release.URL != server.URL+"/series/some-show#season2" {
+		t.Errorf("unexpected URL %s", release.URL)
+	}
+
+	if release.ImageURL != "https://example.com/poster.jpg" || release.Network != "HBO" || !release.Grabbed {
+		t.Errorf("unexpected release %+v", release)
+	}
+
+	if release.Quality != "WEBDL-1080p" || release.FileSize != 2254857830 {
+		t.Errorf("unexpected episode file quality %q and size %d", release.Quality, release.FileSize)
+	}
+}
+
+func TestFetchReleasesFromRadarr(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, mockRadarrCalendarJson)
+	request := &ArrReleaseRequest{Source: ArrSourceRadarr, URL: server.URL, APIKey: "secret", OverviewLength: 140}
+	start, end := mockArrWindow()
+
+	releases, err := fetchReleasesFromRadarr(context.Background(), request, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if apiKey := server.lastRequest().apiKey; apiKey != "secret" {
+		t.Errorf("expected X-Api-Key header to be secret, got %q", apiKey)
+	}
+
+	if len(releases) != 1 {
+		t.Fatalf("expected 1 release, got %d", len(releases))
+	}
+
+	release := releases[0]
+
+	if release.Title != "Some Movie" || release.ReleaseType != "Digital" {
+		t.Errorf("unexpected title and release type %q / %q", release.Title, release.ReleaseType)
+	}
+
+	if release.Status != "Available" {
+		t.Errorf("expected status Available, got %q", release.Status)
+	}
+
+	if release.URL != server.URL+"/movie/some-movie-2024" || release.Runtime != 120 || release.Certification != "PG-13" {
+		t.Errorf("unexpected release %+v", release)
+	}
+
+	if release.Overview != "Something happens to someone." {
+		t.Errorf("expected trimmed overview, got %q", release.Overview)
+	}
+
+	if release.IMDbURL != "https://www.imdb.com/title/tt0000001/" || release.TMDbURL != "https://www.themoviedb.org/movie/12345" {
+		t.Errorf("unexpected external links %q / %q", release.IMDbURL, release.TMDbURL)
+	}
+}
+
+func TestFetchReleasesFromRadarrWithMovieFile(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, `[
+		{
+			"title": "Grabbed Movie",
+			"digitalRelease": "2024-05-10T00:00:00Z",
+			"hasFile": true,
+			"movieFile": {"size": 16106127360, "quality": {"quality": {"name": "Bluray-2160p", "resolution": 2160}}}
+		},
+		{
+			"title": "Grabbed Movie Without File Info",
+			"digitalRelease": "2024-05-10T00:00:00Z",
+			"hasFile": true
+		}
+	]`)
+	start, end := mockArrWindow()
+
+	releases, err := fetchReleasesFromRadarr(context.Background(), &ArrReleaseRequest{Source: ArrSourceRadarr, URL: server.URL}, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(releases) != 2 {
+		t.Fatalf("expected 2 releases, got %d", len(releases))
+	}
+
+	if releases[0].Quality != "Bluray-2160p" || releases[0].FileSize != 16106127360 {
+		t.Errorf("unexpected movie file quality %q and size %d", releases[0].Quality, releases[0].FileSize)
+	}
+
+	if !releases[1].Grabbed || releases[1].Quality != "" || releases[1].FileSize != 0 {
+		t.Errorf("expected a grabbed release without file details, got %+v", releases[1])
+	}
+}
+
+func TestFetchReleasesFromArrWithSelfSignedCertificate(t *testing.T) {
+	server := newMockArrServer(t, true, http.StatusOK, mockSonarrCalendarJson)
+	start, end := mockArrWindow()
+
+	_, err := fetchReleasesFromSonarr(context.Background(), &ArrReleaseRequest{Source: ArrSourceSonarr, URL: server.URL}, start, end)
+
+	if err == nil {
+		t.Error("expected an error for a self-signed certificate without allow-insecure")
+	}
+
+	releases, err := fetchReleasesFromSonarr(context.Background(), &ArrReleaseRequest{Source: ArrSourceSonarr, URL: server.URL, AllowInsecure: true}, start, end)
+
+	if err != nil {
+		t.Fatalf("expected no error with allow-insecure, got %v", err)
+	}
+
+	if len(releases) != 1 {
+		t.Errorf("expected 1 release, got %d", len(releases))
+	}
+}
+
+func TestFetchReleasesFromArrWithBadResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"invalid json", http.StatusOK, `[{"title": `},
+		{"unexpected type", http.StatusOK, `{"title": "not a list"}`},
+		{"unauthorized", http.StatusUnauthorized, `{"error": "Unauthorized"}`},
+		{"server error", http.StatusInternalServerError, `oops`},
+	}
+
+	fetchers := map[ArrSource]func(context.Context, *ArrReleaseRequest, time.Time, time.Time) (ArrReleases, error){
+		ArrSourceSonarr: fetchReleasesFromSonarr,
+		ArrSourceRadarr: fetchReleasesFromRadarr,
+	}
+
+	for _, test := range tests {
+		for source, fetch := range fetchers {
+			t.Run(string(source)+"/"+test.name, func(t *testing.T) {
+				server := newMockArrServer(t, false, test.status, test.body)
+				start, end := mockArrWindow()
+
+				releases, err := fetch(context.Background(), &ArrReleaseRequest{Source: source, URL: server.URL}, start, end)
+
+				if err == nil {
+					t.Fatalf("expected an error, got %d releases", len(releases))
+				}
+
+				if !strings.Contains(err.Error(), test.body) {
+					t.Errorf("expected the error to include the response body, got %v", err)
+				}
+
+				if test.status != http.StatusOK && !strings.Contains(err.Error(), http.StatusText(test.status)) {
+					t.Errorf("expected the error to include the status text, got %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestFetchReleasesFromArrStackWithPartialFailure(t *testing.T) {
+	working := newMockArrServer(t, false, http.StatusOK, mockSonarrCalendarJson)
+	failing := newMockArrServer(t, false, http.StatusInternalServerError, "oops")
+
+	releases, err := FetchReleasesFromArrStack(context.Background(), slog.Default(), []*ArrReleaseRequest{
+		{Source: ArrSourceSonarr, URL: working.URL},
+		{Source: ArrSourceRadarr, URL: failing.URL},
+	}, time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC))
+
+	if !errors.Is(err, ErrPartialContent) {
+		t.Errorf("expected partial content error, got %v", err)
+	}
+
+	if len(releases) != 1 {
+		t.Errorf("expected 1 release, got %d", len(releases))
+	}
+}
+
+func TestFetchReleasesFromArrStackWithFailingRadarr(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusUnauthorized, `{"error": "Unauthorized"}`)
+
+	releases, err := FetchReleasesFromArrStack(context.Background(), slog.Default(), []*ArrReleaseRequest{
+		{Source: ArrSourceRadarr, URL: server.URL},
+	}, time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC))
+
+	if !errors.Is(err, ErrNoContent) {
+		t.Fatalf("expected no content error, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "401") {
+		t.Errorf("expected the error to include the cause, got %v", err)
+	}
+
+	if releases != nil {
+		t.Errorf("expected no releases, got %d", len(releases))
+	}
+}
+
+func TestFetchReleasesFromArrStackKeepsOverviews(t *testing.T) {
+	sonarr := newMockArrServer(t, false, http.StatusOK, mockSonarrCalendarJson)
+	radarr := newMockArrServer(t, false, http.StatusOK, mockRadarrCalendarJson)
+
+	releases, err := FetchReleasesFromArrStack(context.Background(), slog.Default(), []*ArrReleaseRequest{
+		{Source: ArrSourceSonarr, URL: sonarr.URL, OverviewLength: 140},
+		{Source: ArrSourceRadarr, URL: radarr.URL, OverviewLength: 140},
+	}, time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(releases) != 2 {
+		t.Fatalf("expected 2 releases, got %d", len(releases))
+	}
+
+	for _, release := range releases {
+		if release.Overview == "" {
+			t.Errorf("expected an overview for %s release %q", release.Source, release.Title)
+		}
+	}
+}
+
+func TestFetchSonarrPremieres(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, `[
+		{"seasonNumber": 1, "episodeNumber": 1, "airDateUtc": "2024-05-12T20:00:00Z", "series": {"title": "New Show"}},
+		{"seasonNumber": 3, "episodeNumber": 1, "airDateUtc": "2024-05-11T20:00:00Z", "series": {"title": "Old Show"}},
+		{"seasonNumber": 3, "episodeNumber": 2, "airDateUtc": "2024-05-13T20:00:00Z", "series": {"title": "Old Show"}},
+		{"seasonNumber": 0, "episodeNumber": 1, "airDateUtc": "2024-05-13T20:00:00Z", "series": {"title": "Old Show"}},
+		{"seasonNumber": 2, "episodeNumber": 1, "airDateUtc": "2024-05-20T20:00:00Z", "series": {"title": "Later Show"}}
+	]`)
+
+	premieres, err := FetchSonarrPremieres(context.Background(),
+		&ArrReleaseRequest{Source: ArrSourceSonarr, URL: server.URL},
+		time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC),
+		7,
+	)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(premieres) != 2 {
+		t.Fatalf("expected 2 premieres, got %d", len(premieres))
+	}
+
+	if premieres[0].Title != "Old Show" || premieres[0].ReleaseType != "Season Premiere" {
+		t.Errorf("unexpected first premiere %q / %q", premieres[0].Title, premieres[0].ReleaseType)
+	}
+
+	if premieres[1].Title != "New Show" || premieres[1].ReleaseType != "Series Premiere" {
+		t.Errorf("unexpected second premiere %q / %q", premieres[1].Title, premieres[1].ReleaseType)
+	}
+}
+
+func TestFetchReleasesFromArrWithLinkBase(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, mockRadarrCalendarJson)
+	request := &ArrReleaseRequest{Source: ArrSourceRadarr, URL: server.URL, LinkBase: "https://radarr.example.com/"}
+	start, end := mockArrWindow()
+
+	releases, err := fetchReleasesFromRadarr(context.Background(), request, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(releases) != 1 || releases[0].URL != "https://radarr.example.com/movie/some-movie-2024" {
+		t.Errorf("expected link to use the link base, got %+v", releases)
+	}
+}
+
+func TestFetchReleasesFromArrUserAgent(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, "[]")
+	start, end := mockArrWindow()
+
+	if _, err := fetchReleasesFromRadarr(context.Background(), &ArrReleaseRequest{Source: ArrSourceRadarr, URL: server.URL}, start, end); err != nil {
+		t.Fatal(err)
+	}
+
+	if userAgent := server.lastRequest().userAgent; userAgent != GlanceUserAgent {
+		t.Errorf("expected default user agent %q, got %q", GlanceUserAgent, userAgent)
+	}
+
+	if _, err := fetchReleasesFromRadarr(context.Background(), &ArrReleaseRequest{Source: ArrSourceRadarr, URL: server.URL, UserAgent: "custom"}, start, end); err != nil {
+		t.Fatal(err)
+	}
+
+	if userAgent := server.lastRequest().userAgent; userAgent != "custom" {
+		t.Errorf("expected user agent custom, got %q", userAgent)
+	}
+}
+
+func TestFetchReleasesFromSonarrWithExtraQuery(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, "[]")
+	start, end := mockArrWindow()
+
+	request := &ArrReleaseRequest{
+		Source:     ArrSourceSonarr,
+		URL:        server.URL,
+		ExtraQuery: map[string]string{"unmonitored": "true", "includeEpisodeFile": "false"},
+	}
+
+	if _, err := fetchReleasesFromSonarr(context.Background(), request, start, end); err != nil {
+		t.Fatal(err)
+	}
+
+	query, err := url.ParseQuery(server.lastRequest().query)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query.Get("unmonitored") != "true" || query.Get("includeEpisodeFile") != "false" || query.Get("includeSeries") != "true" {
+		t.Errorf("expected the extra query to be added to the defaults, got %s", server.lastRequest().query)
+	}
+}
+
+func TestFetchReleasesFromArrWithHeaders(t *testing.T) {
+	var header string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("CF-Access-Client-Id")
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	start, end := mockArrWindow()
+	request := &ArrReleaseRequest{Source: ArrSourceRadarr, URL: server.URL, Headers: map[string]string{"CF-Access-Client-Id": "client"}}
+
+	if _, err := fetchReleasesFromRadarr(context.Background(), request, start, end); err != nil {
+		t.Fatal(err)
+	}
+
+	if header != "client" {
+		t.Errorf("expected header to be sent, got %q", header)
+	}
+}
+
+func TestArrRequestsAreLimitedPerHost(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		w.Write([]byte("[]"))
+	}))
+	t.Cleanup(server.Close)
+
+	start, end := mockArrWindow()
+	var wg sync.WaitGroup
+
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fetchReleasesFromRadarr(context.Background(), &ArrReleaseRequest{Source: ArrSourceRadarr, URL: server.URL}, start, end)
+		}()
+	}
+
+	wg.Wait()
+
+	if maxInFlight > arrMaxConcurrentRequestsPerHost {
+		t.Errorf("expected at most %d requests in flight, got %d", arrMaxConcurrentRequestsPerHost, maxInFlight)
+	}
+}
+
+func TestFetchSonarrNextAiring(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, mockSonarrCalendarJson)
+	request := &ArrReleaseRequest{Source: ArrSourceSonarr, URL: server.URL}
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	nextAiring, err := FetchSonarrNextAiring(context.Background(), slog.Default(), []*ArrReleaseRequest{request}, now, 7, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nextAiring) != 1 {
+		t.Fatalf("expected 1 next airing episode, got %d", len(nextAiring))
+	}
+
+	if nextAiring[0].Release.EpisodeNumber != 6 || nextAiring[0].DaysUntil != 1 {
+		t.Errorf("unexpected next airing episode %+v", nextAiring[0])
+	}
+
+	nextAiring, err = FetchSonarrNextAiring(context.Background(), slog.Default(), []*ArrReleaseRequest{request}, now, 7, []string{"Another Show"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nextAiring) != 0 {
+		t.Errorf("expected episodes of other series to be excluded, got %d", len(nextAiring))
+	}
+}
+
+func TestQueryArrPagedApi(t *testing.T) {
+	const totalRecords = 120
+	var requestedPages []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		requestedPages = append(requestedPages, r.URL.Query().Get("page"))
+
+		records := make([]string, 0, pageSize)
+
+		for i := (page - 1) * pageSize; i < min(page*pageSize, totalRecords); i++ {
+			records = append(records, fmt.Sprintf(`{"id": %d}`, i))
+		}
+
+		fmt.Fprintf(w, `{"page": %d, "pageSize": %d, "totalRecords": %d, "records": [%s]}`, page, pageSize, totalRecords, strings.Join(records, ","))
+	}))
+	t.Cleanup(server.Close)
+
+	type record struct {
+		ID int `json:"id"`
+	}
+
+	// skips discovering the url base, which would be counted as a request
+	request := &ArrReleaseRequest{Source: ArrSourceSonarr, URL: server.URL, URLBase: "/"}
+	records, total, err := queryArrPagedApi[record](context.Background(), request, "/api/v3/queue", nil, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != totalRecords || total != totalRecords || records[totalRecords-1].ID != totalRecords-1 {
+		t.Errorf("expected all %d records, got %d with a total of %d", totalRecords, len(records), total)
+	}
+
+	if strings.Join(requestedPages, ",") != "1,2,3" {
+		t.Errorf("expected pages 1,2,3 to be requested, got %v", requestedPages)
+	}
+
+	requestedPages = nil
+	records, _, err = queryArrPagedApi[record](context.Background(), request, "/api/v3/queue", nil, 60)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 60 || len(requestedPages) != 2 {
+		t.Errorf("expected 60 records from 2 pages, got %d from %d", len(records), len(requestedPages))
+	}
+}
+
+func TestCheckArrConnection(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, `{"appName": "Lidarr", "version": "2.5.3"}`)
+
+	details, err := CheckArrConnection(&ArrReleaseRequest{Source: ArrSourceLidarr, URL: server.URL, APIKey: "secret"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if details != "Lidarr 2.5.3" {
+		t.Errorf("unexpected details %q", details)
+	}
+
+	if path := server.lastRequest().path; path != "/api/v1/system/status" {
+		t.Errorf("expected path /api/v1/system/status, got %s", path)
+	}
+
+	failing := newMockArrServer(t, false, http.StatusUnauthorized, `{"error": "Unauthorized"}`)
+
+	if _, err := CheckArrConnection(&ArrReleaseRequest{Source: ArrSourceSonarr, URL: failing.URL}); err == nil {
+		t.Error("expected an error for an invalid api key")
+	}
+}
+
+func TestFetchSonarrStats(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, `[
+		{"monitored": true, "statistics": {"episodeFileCount": 10, "episodeCount": 12, "sizeOnDisk": 1000}},
+		{"monitored": false, "statistics": {"episodeFileCount": 5, "episodeCount": 5, "sizeOnDisk": 500}}
+	]`)
+
+	stats, err := FetchSonarrStats(context.Background(), &ArrReleaseRequest{Source: ArrSourceSonarr, URL: server.URL})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if server.lastRequest().path != "/api/v3/series" {
+		t.Errorf("expected path /api/v3/series, got %s", server.lastRequest().path)
+	}
+
+	expected := SonarrStats{Series: 2, MonitoredCount: 1, EpisodesOnDisk: 15, TotalEpisodes: 17, SizeOnDisk: 1500}
+
+	if *stats != expected {
+		t.Errorf("expected %+v, got %+v", expected, *stats)
+	}
+}
+
+func TestFetchReleasesFromRadarrByAvailability(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, mockRadarrCalendarJson)
+	start, end := mockArrWindow()
+
+	for availability, expected := range map[string]int{"": 1, "any": 1, "released": 1, "announced": 0, "inCinemas": 0} {
+		request := &ArrReleaseRequest{Source: ArrSourceRadarr, URL: server.URL, Availability: availability}
+		releases, err := fetchReleasesFromRadarr(context.Background(), request, start, end)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(releases) != expected {
+			t.Errorf("availability %q: expected %d releases, got %d", availability, expected, len(releases))
+		}
+	}
+}
+
+func TestFetchReleasesFromSonarrWithDiscoveredURLBase(t *testing.T) {
+	var mu sync.Mutex
+	var requestedPaths []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requestedPaths = append(requestedPaths, r.URL.Path)
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/api/v3/system/status":
+			w.Write([]byte(`{"appName": "Sonarr", "version": "4.0.0", "urlBase": "/sonarr"}`))
+		case "/sonarr/api/v3/calendar":
+			w.Write([]byte(mockSonarrCalendarJson))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	start, end := mockArrWindow()
+
+	for range 2 {
+		releases, err := fetchReleasesFromSonarr(context.Background(), &ArrReleaseRequest{Source: ArrSourceSonarr, URL: server.URL}, start, end)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(releases) != 1 || releases[0].URL != server.URL+"/sonarr/series/some-show#season2" {
+			t.Fatalf("expected link to include the url base, got %+v", releases)
+		}
+	}
+
+	expected := "/api/v3/system/status,/sonarr/api/v3/calendar,/sonarr/api/v3/calendar"
+
+	if paths := strings.Join(requestedPaths, ","); paths != expected {
+		t.Errorf("expected the url base to be discovered once, got requests to %s", paths)
+	}
+}
+
+func TestJoinArrURLBase(t *testing.T) {
+	tests := []struct {
+		url, urlBase, expected string
+	}{
+		{"http://localhost:8989", "", "http://localhost:8989"},
+		{"http://localhost:8989/", "/sonarr", "http://localhost:8989/sonarr"},
+		{"http://localhost:8989/sonarr/", "/sonarr", "http://localhost:8989/sonarr"},
+		{"http://localhost:8989", "/", "http://localhost:8989"},
+	}
+
+	for _, test := range tests {
+		if joined := joinArrURLBase(test.url, test.urlBase); joined != test.expected {
+			t.Errorf("joining %q and %q: expected %q, got %q", test.url, test.urlBase, test.expected, joined)
+		}
+	}
+}
+
+func TestFetchSonarrCutoffUnmet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/wanted/cutoff":
+			w.Write([]byte(`{"page": 1, "pageSize": 50, "totalRecords": 2, "records": [
+				{
+					"seasonNumber": 1, "episodeNumber": 3, "title": "Pilot", "airDateUtc": "2024-05-01T20:00:00Z",
+					"episodeFile": {"dateAdded": "2024-05-02T08:00:00Z", "quality": {"quality": {"name": "HDTV-720p"}}},
+					"series": {"title": "Some Show", "titleSlug": "some-show", "qualityProfileId": 1}
+				},
+				{
+					"seasonNumber": 2, "episodeNumber": 1, "airDateUtc": "2024-04-01T20:00:00Z",
+					"series": {"title": "Other Show", "titleSlug": "other-show", "qualityProfileId": 2}
+				}
+			]}`))
+		case "/api/v3/qualityprofile":
+			w.Write([]byte(`[
+				{"id": 1, "cutoff": 7, "items": [{"quality": {"id": 4, "name": "HDTV-720p"}}, {"quality": {"id": 7, "name": "Bluray-1080p"}}]},
+				{"id": 2, "cutoff": 1000, "items": [{"id": 1000, "name": "WEB 1080p", "items": []}]}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	request := &ArrReleaseRequest{Source: ArrSourceSonarr, URL: server.URL, URLBase: "/"}
+	cutoffUnmet, err := FetchSonarrCutoffUnmet(context.Background(), request, 10)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cutoffUnmet.Total != 2 || len(cutoffUnmet.Episodes) != 2 {
+		t.Fatalf("expected 2 episodes, got %+v", cutoffUnmet)
+	}
+
+	first := cutoffUnmet.Episodes[0]
+
+	if first.Quality != "HDTV-720p" || first.CutoffQuality != "Bluray-1080p" || first.Subtitle != "S01E03 · Pilot" {
+		t.Errorf("unexpected first episode %+v", first)
+	}
+
+	if !first.FileAddedAt.Equal(time.Date(2024, 5, 2, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected file added date %v", first.FileAddedAt)
+	}
+
+	if second := cutoffUnmet.Episodes[1]; second.CutoffQuality != "WEB 1080p" || second.Quality != "" {
+		t.Errorf("expected the cutoff of a quality group, got %+v", second)
+	}
+}
+
+func TestFetchReleasesFromArrStackStopsAtDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	requests := []*ArrReleaseRequest{
+		{Source: ArrSourceSonarr, URL: server.URL, URLBase: "/"},
+		{Source: ArrSourceRadarr, URL: server.URL, URLBase: "/"},
+	}
+
+	started := time.Now()
+	_, err := FetchReleasesFromArrStack(ctx, slog.Default(), requests, time.Now())
+
+	if !errors.Is(err, ErrNoContent) {
+		t.Errorf("expected ErrNoContent, got %v", err)
+	}
+
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("expected the requests to be cancelled at the deadline, took %s", elapsed)
+	}
+}
+
+func TestFetchRadarrCollectionMovies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/collection":
+			w.Write([]byte(`[
+				{"title": "Some Collection", "monitored": true, "movies": [
+					{"tmdbId": 1, "title": "Part One", "year": 2020},
+					{"tmdbId": 2, "title": "Part Two", "year": 2024},
+					{"tmdbId": 3, "title": "Part Three", "year": 2025, "images": [{"coverType": "poster", "remoteUrl": "https://example.com/3.jpg"}]},
+					{"tmdbId": 4, "title": "Part Four", "year": 2026},
+					{"tmdbId": 5, "title": "Part Five", "year": 0},
+					{"tmdbId": 6, "title": "Excluded Part", "year": 2026, "isExcluded": true}
+				]},
+				{"title": "Unmonitored Collection", "monitored": false, "movies": [
+					{"tmdbId": 7, "title": "Unmonitored Movie", "year": 2026}
+				]}
+			]`))
+		case "/api/v3/movie":
+			w.Write([]byte(`[
+				{"tmdbId": 1, "titleSlug": "part-one", "status": "released", "hasFile": true},
+				{"tmdbId": 2, "titleSlug": "part-two", "status": "inCinemas", "hasFile": false},
+				{"tmdbId": 4, "titleSlug": "part-four", "status": "announced", "hasFile": false}
+			]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	request := &ArrReleaseRequest{Source: ArrSourceRadarr, URL: server.URL, URLBase: "/"}
+	movies, err := FetchRadarrCollectionMovies(context.Background(), request, 10, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+
+	for _, movie := range movies {
+		got = append(got, movie.Title+" ("+movie.Status+")")
+	}
+
+	// part two is still in cinemas despite being from a previous year since it's in the library
+	expected := "Part Two (In Cinemas), Part Three (Not in Library), Part Four (Announced), Part Five (Not in Library)"
+
+	if joined := strings.Join(got, ", "); joined != expected {
+		t.Errorf("expected %s, got %s", expected, joined)
+	}
+
+	if movies[1].URL != server.URL+"/add/new?term=tmdb%3A3" || movies[1].ImageURL != "https://example.com/3.jpg" {
+		t.Errorf("unexpected movie not in library %+v", movies[1])
+	}
+
+	if movies[2].URL != server.URL+"/movie/part-four" || !movies[2].InLibrary {
+		t.Errorf("unexpected movie in library %+v", movies[2])
+	}
+}
+
+func TestFetchReleasesFromSonarrWithoutImages(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, `[
+		{
+			"seasonNumber": 1,
+			"episodeNumber": 1,
+			"airDateUtc": "2024-05-10T20:00:00Z",
+			"series": {"title": "New Show", "titleSlug": "new-show", "images": []}
+		},
+		{
+			"seasonNumber": 1,
+			"episodeNumber": 1,
+			"airDateUtc": "2024-05-10T21:00:00Z",
+			"series": {"title": "Newer Show", "titleSlug": "newer-show", "images": [{"coverType": "poster", "url": "/MediaCover/2/poster.jpg"}]}
+		}
+	]`)
+	start, end := mockArrWindow()
+
+	// the poster url template and image type shouldn't turn a missing image into a broken one
+	request := &ArrReleaseRequest{
+		Source:            ArrSourceSonarr,
+		URL:               server.URL,
+		URLBase:           "/",
+		PosterURLTemplate: "https://proxy.example.com/?url={remoteUrlEncoded}",
+		ImageType:         "fanart",
+		ThumbnailSize:     342,
+	}
+
+	releases, err := fetchReleasesFromSonarr(context.Background(), request, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(releases) != 2 {
+		t.Fatalf("expected 2 releases, got %d", len(releases))
+	}
+
+	for _, release := range releases {
+		if release.ImageURL != "" {
+			t.Errorf("expected %s to have no image, got %s", release.Title, release.ImageURL)
+		}
+	}
+}
+
+func TestFetchReleasesFromSonarrWithEpisodeImages(t *testing.T) {
+	server := newMockArrServer(t, false, http.StatusOK, `[
+		{
+			"seasonNumber": 1,
+			"episodeNumber": 1,
+			"airDateUtc": "2024-05-10T20:00:00Z",
+			"images": [{"coverType": "screenshot", "remoteUrl": "https://artworks.thetvdb.com/banners/episodes/1/1.jpg"}],
+			"series": {"title": "Aired Show", "titleSlug": "aired-show", "images": [{"coverType": "fanart", "remoteUrl": "https://artworks.thetvdb.com/banners/fanart/original/1.jpg"}]}
+		},
+		{
+			"seasonNumber": 1,
+			"episodeNumber": 2,
+			"airDateUtc": "2024-05-10T21:00:00Z",
+			"series": {"title": "Upcoming Show", "titleSlug": "upcoming-show", "images": [{"coverType": "fanart", "remoteUrl": "https://artworks.thetvdb.com/banners/fanart/original/2.jpg"}]}
+		}
+	]`)
+	start, end := mockArrWindow()
+
+	request := &ArrReleaseRequest{
+		Source:      ArrSourceSonarr,
+		URL:         server.URL,
+		URLBase:     "/",
+		ImageType:   "fanart",
+		ImageSource: "episode",
+	}
+
+	releases, err := fetchReleasesFromSonarr(context.Background(), request, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query := server.lastRequest().query; !strings.Contains(query, "includeEpisodeImages=true") {
+		t.Errorf("expected includeEpisodeImages in query, got %s", query)
+	}
+
+	if len(releases) != 2 {
+		t.Fatalf("expected 2 releases, got %d", len(releases))
+	}
+
+	expected := map[string]string{
+		"Aired Show":    "/banners/episodes/1/1.jpg",
+		"Upcoming Show": "/banners/fanart/original/2.jpg",
+	}
+
+	for _, release := range releases {
+		if !strings.HasSuffix(release.ImageURL, expected[release.Title]) {
+			t.Errorf("expected image of %s to end with %s, got %s", release.Title, expected[release.Title], release.ImageURL)
+		}
+	}
+}
+
+func TestFetchReleasesFromArrWithEmptyResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"empty body", http.StatusOK, ``},
+		{"whitespace body", http.StatusOK, " \n"},
+		{"no content", http.StatusNoContent, ``},
+	}
+
+	fetchers := map[ArrSource]func(context.Context, *ArrReleaseRequest, time.Time, time.Time) (ArrReleases, error){
+		ArrSourceSonarr: fetchReleasesFromSonarr,
+		ArrSourceRadarr: fetchReleasesFromRadarr,
+		ArrSourceLidarr: fetchReleasesFromLidarr,
+	}
+
+	for _, test := range tests {
+		for source, fetch := range fetchers {
+			t.Run(string(source)+"/"+test.name, func(t *testing.T) {
+				server := newMockArrServer(t, false, test.status, test.body)
+				start, end := mockArrWindow()
+
+				releases, err := fetch(context.Background(), &ArrReleaseRequest{Source: source, URL: server.URL}, start, end)
+
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+
+				if len(releases) != 0 {
+					t.Errorf("expected no releases, got %d", len(releases))
+				}
+			})
+		}
+	}
+}
+
+func TestFetchJellyfinPlayedEpisodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Emby-Token") != "key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.URL.Query().Get("ids") != "" {
+			w.Write([]byte(`{"Items": [{"Id": "series-1", "ProviderIds": {"Tvdb": "100"}}]}`))
+			return
+		}
+
+		if r.URL.Query().Get("isPlayed") != "true" || r.URL.Query().Get("minPremiereDate") == "" {
+			t.Errorf("expected played episodes since a date to be requested, got %s", r.URL.RawQuery)
+		}
+
+		w.Write([]byte(`{"Items": [
+			{"Id": "a", "SeriesId": "series-1", "ParentIndexNumber": 2, "IndexNumber": 3, "ProviderIds": {}},
+			{"Id": "b", "SeriesId": "series-2", "ParentIndexNumber": 1, "IndexNumber": 1, "ProviderIds": {"Tvdb": "5000"}}
+		]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	request := &JellyfinRequest{URL: server.URL, APIKey: "key", UserID: "user"}
+	played, err := FetchJellyfinPlayedEpisodes(context.Background(), request, time.Now())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name                                  string
+		tvdbID, seriesTVDbID, season, episode int
+		expected                              bool
+	}{
+		{"by series and number", 0, 100, 2, 3, true},
+		{"by episode id", 5000, 200, 1, 1, true},
+		{"other episode of series", 0, 100, 2, 4, false},
+		{"unknown ids", 0, 0, 2, 3, false},
+	}
+
+	for _, test := range tests {
+		if got := played.Contains(test.tvdbID, test.seriesTVDbID, test.season, test.episode); got != test.expected {
+			t.Errorf("%s: expected %t, got %t", test.name, test.expected, got)
+		}
+	}
+}
@@ -0,0 +1,598 @@
+package feed
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSonarrTestResponse(t *testing.T, airDates ...string) sonarrCalendarResponseJson {
+	t.Helper()
+
+	episodes := make([]map[string]any, 0, len(airDates))
+
+	for i, airDate := range airDates {
+		episodes = append(episodes, map[string]any{
+			"seasonNumber":  1,
+			"episodeNumber": i + 1,
+			"airDateUtc":    airDate,
+			"series":        map[string]any{"title": "Show", "titleSlug": "show"},
+		})
+	}
+
+	data, err := json.Marshal(episodes)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var response sonarrCalendarResponseJson
+
+	if err := json.Unmarshal(data, &response); err != nil {
+		t.Fatal(err)
+	}
+
+	return response
+}
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+
+	location, err := time.LoadLocation(name)
+
+	if err != nil {
+		t.Skipf("time zone %s is not available: %v", name, err)
+	}
+
+	return location
+}
+
+func TestSonarrReleasesWithinWindow(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	minusFive := time.FixedZone("UTC-5", -5*60*60)
+	plusNine := time.FixedZone("UTC+9", 9*60*60)
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		airDates []string
+		expected []int
+	}{
+		{
+			name: "late evening with negative offset",
+			now:  time.Date(2024, 5, 10, 23, 30, 0, 0, minusFive),
+			airDates: []string{
+				"2024-05-10T04:59:59Z", // 23:59:59 the previous day
+				"2024-05-10T05:00:00Z", // midnight
+				"2024-05-11T04:00:00Z", // 23:00, already the next day in UTC
+				"2024-05-11T04:59:59Z", // 23:59:59
+				"2024-05-11T05:00:00Z", // midnight of the next day
+			},
+			expected: []int{2, 3, 4},
+		},
+		{
+			name: "just after midnight",
+			now:  time.Date(2024, 5, 11, 0, 10, 0, 0, minusFive),
+			airDates: []string{
+				"2024-05-11T04:50:00Z", // 23:50 the previous day
+				"2024-05-11T05:30:00Z", // 00:30
+			},
+			expected: []int{2},
+		},
+		{
+			name: "early morning with positive offset",
+			now:  time.Date(2024, 5, 11, 1, 0, 0, 0, plusNine),
+			airDates: []string{
+				"2024-05-10T14:59:59Z", // 23:59:59 the previous day
+				"2024-05-10T15:00:00Z", // midnight, still the previous day in UTC
+				"2024-05-11T14:00:00Z", // 23:00
+			},
+			expected: []int{2, 3},
+		},
+		{
+			name: "spring forward",
+			now:  time.Date(2024, 3, 10, 12, 0, 0, 0, newYork),
+			airDates: []string{
+				"2024-03-10T04:30:00Z", // 23:30 EST the previous day
+				"2024-03-10T05:00:00Z", // midnight EST
+				"2024-03-11T03:30:00Z", // 23:30 EDT
+				"2024-03-11T04:00:00Z", // midnight EDT of the next day
+			},
+			expected: []int{2, 3},
+		},
+		{
+			name: "fall back",
+			now:  time.Date(2024, 11, 3, 12, 0, 0, 0, newYork),
+			airDates: []string{
+				"2024-11-03T03:30:00Z", // 23:30 EDT the previous day
+				"2024-11-03T04:00:00Z", // midnight EDT
+				"2024-11-04T04:30:00Z", // 23:30 EST
+				"2024-11-04T05:00:00Z", // midnight EST of the next day
+			},
+			expected: []int{2, 3},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			start, end := getArrReleasesWindow(test.now)
+			request := &ArrReleaseRequest{Source: ArrSourceSonarr}
+
+			releases, err := sonarrReleasesFromResponse(request, newSonarrTestResponse(t, test.airDates...), start, end)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			episodes := make([]int, 0, len(releases))
+
+			for i := range releases {
+				episodes = append(episodes, releases[i].EpisodeNumber)
+			}
+
+			if len(episodes) != len(test.expected) {
+				t.Fatalf("expected episodes %v, got %v", test.expected, episodes)
+			}
+
+			for i := range episodes {
+				if episodes[i] != test.expected[i] {
+					t.Fatalf("expected episodes %v, got %v", test.expected, episodes)
+				}
+			}
+		})
+	}
+}
+
+func TestSonarrReleasesWithoutAirDate(t *testing.T) {
+	start, end := getArrReleasesWindow(time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC))
+	response := newSonarrTestResponse(t, "2024-05-10T20:00:00Z", "", "2024-05-10T21:00:00Z", "2024-05-10 22:00")
+
+	releases, err := sonarrReleasesFromResponse(&ArrReleaseRequest{Source: ArrSourceSonarr}, response, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(releases) != 2 || releases[0].EpisodeNumber != 1 || releases[1].EpisodeNumber != 3 {
+		t.Errorf("expected the episodes without a valid air date to be skipped, got %+v", releases)
+	}
+}
+
+func TestArrCalendarQueryPadsWindow(t *testing.T) {
+	now := time.Date(2024, 5, 10, 23, 30, 0, 0, time.FixedZone("UTC-5", -5*60*60))
+	query := arrCalendarQuery(getArrReleasesWindow(now))
+
+	if got := query.Get("start"); got != "2024-05-09T05:00:00Z" {
+		t.Errorf("expected start 2024-05-09T05:00:00Z, got %s", got)
+	}
+
+	if got := query.Get("end"); got != "2024-05-12T05:00:00Z" {
+		t.Errorf("expected end 2024-05-12T05:00:00Z, got %s", got)
+	}
+}
+
+func TestArrReleasesWindowOnSpringForward(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	for _, now := range []time.Time{
+		time.Date(2024, 3, 10, 0, 30, 0, 0, newYork),
+		time.Date(2024, 3, 10, 3, 30, 0, 0, newYork),
+		time.Date(2024, 3, 10, 23, 30, 0, 0, newYork),
+	} {
+		start, end := getArrReleasesWindow(now)
+
+		if got := start.UTC().Format(time.RFC3339); got != "2024-03-10T05:00:00Z" {
+			t.Errorf("now %s: expected start 2024-03-10T05:00:00Z, got %s", now, got)
+		}
+
+		if got := end.UTC().Format(time.RFC3339); got != "2024-03-11T04:00:00Z" {
+			t.Errorf("now %s: expected end 2024-03-11T04:00:00Z, got %s", now, got)
+		}
+
+		if length := end.Sub(start); length != 23*time.Hour {
+			t.Errorf("now %s: expected a 23 hour window, got %s", now, length)
+		}
+
+		query := arrCalendarQuery(start, end)
+
+		if got := query.Get("start"); got != "2024-03-09T05:00:00Z" {
+			t.Errorf("now %s: expected query start 2024-03-09T05:00:00Z, got %s", now, got)
+		}
+
+		if got := query.Get("end"); got != "2024-03-12T04:00:00Z" {
+			t.Errorf("now %s: expected query end 2024-03-12T04:00:00Z, got %s", now, got)
+		}
+	}
+}
+
+func TestArrReleaseWithinLastSecondOfDay(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	start, end := getArrReleasesWindow(now)
+
+	if !isWithinWindow(time.Date(2024, 5, 10, 23, 59, 59, 500_000_000, time.UTC), start, end) {
+		t.Error("expected 23:59:59.5 to be within the window")
+	}
+
+	if isWithinWindow(time.Date(2024, 5, 11, 0, 0, 0, 0, time.UTC), start, end) {
+		t.Error("expected midnight of the next day to be outside the window")
+	}
+}
+
+func TestArrReleaseRequestWindowWithOffsets(t *testing.T) {
+	now := time.Date(2024, 5, 10, 23, 30, 0, 0, time.FixedZone("UTC-5", -5*60*60))
+
+	tests := []struct {
+		dayOffset        int
+		fromPreviousDays int
+		start            string
+		end              string
+	}{
+		{0, 0, "2024-05-10T00:00:00-05:00", "2024-05-11T00:00:00-05:00"},
+		{1, 0, "2024-05-11T00:00:00-05:00", "2024-05-12T00:00:00-05:00"},
+		{-1, 0, "2024-05-09T00:00:00-05:00", "2024-05-10T00:00:00-05:00"},
+		{0, 2, "2024-05-08T00:00:00-05:00", "2024-05-11T00:00:00-05:00"},
+		{1, 1, "2024-05-10T00:00:00-05:00", "2024-05-12T00:00:00-05:00"},
+	}
+
+	for _, test := range tests {
+		request := &ArrReleaseRequest{DayOffset: test.dayOffset, FromPreviousDays: test.fromPreviousDays}
+		start, end := request.window(now)
+
+		if got := start.Format(time.RFC3339); got != test.start {
+			t.Errorf("offset %d, previous %d: expected start %s, got %s", test.dayOffset, test.fromPreviousDays, test.start, got)
+		}
+
+		if got := end.Format(time.RFC3339); got != test.end {
+			t.Errorf("offset %d, previous %d: expected end %s, got %s", test.dayOffset, test.fromPreviousDays, test.end, got)
+		}
+	}
+}
+
+func TestArrReleaseRequestWindowWithDayStartHour(t *testing.T) {
+	location := time.FixedZone("UTC-5", -5*60*60)
+	request := &ArrReleaseRequest{DayStartHour: 4}
+
+	tests := []struct {
+		now   time.Time
+		start string
+		end   string
+	}{
+		{time.Date(2024, 5, 11, 1, 30, 0, 0, location), "2024-05-10T04:00:00-05:00", "2024-05-11T04:00:00-05:00"},
+		{time.Date(2024, 5, 11, 4, 0, 0, 0, location), "2024-05-11T04:00:00-05:00", "2024-05-12T04:00:00-05:00"},
+	}
+
+	for _, test := range tests {
+		start, end := request.window(test.now)
+
+		if got := start.Format(time.RFC3339); got != test.start {
+			t.Errorf("now %v: expected start %s, got %s", test.now, test.start, got)
+		}
+
+		if got := end.Format(time.RFC3339); got != test.end {
+			t.Errorf("now %v: expected end %s, got %s", test.now, test.end, got)
+		}
+	}
+
+	now := time.Date(2024, 5, 11, 1, 30, 0, 0, location)
+
+	if got := relativeDayLabel(now, time.Date(2024, 5, 10, 21, 0, 0, 0, location), 4); got != "Today" {
+		t.Errorf("expected an episode from the previous evening to be Today, got %q", got)
+	}
+
+	if got := relativeDayLabel(now, time.Date(2024, 5, 11, 5, 0, 0, 0, location), 4); got != "Tomorrow" {
+		t.Errorf("expected an episode after the day starts to be Tomorrow, got %q", got)
+	}
+
+	// movies and albums only have a date, which has to fall within the shifted window
+	releasedAt, _ := parseArrReleaseDate("2024-05-10T00:00:00Z", location, 4)
+
+	if start, end := request.window(now); !isWithinWindow(releasedAt, start, end) {
+		t.Errorf("expected release date %v to be within the window", releasedAt)
+	}
+}
+
+func TestArrReleaseRequestPosterURL(t *testing.T) {
+	remoteURL := "https://image.tmdb.org/t/p/original/poster.jpg"
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{"", remoteURL},
+		{"https://cache.local/{remoteUrl}", "https://cache.local/https://image.tmdb.org/t/p/original/poster.jpg"},
+		{"https://cache.local/?url={remoteUrlEncoded}&w=200", "https://cache.local/?url=https%3A%2F%2Fimage.tmdb.org%2Ft%2Fp%2Foriginal%2Fposter.jpg&w=200"},
+	}
+
+	for _, test := range tests {
+		request := &ArrReleaseRequest{PosterURLTemplate: test.template}
+
+		if got := request.posterURL(remoteURL); got != test.expected {
+			t.Errorf("template %q: expected %s, got %s", test.template, test.expected, got)
+		}
+	}
+
+	if got := (&ArrReleaseRequest{PosterURLTemplate: "https://cache.local/{remoteUrl}"}).posterURL(""); got != "" {
+		t.Errorf("expected releases without a poster to stay without one, got %s", got)
+	}
+}
+
+func TestRelativeDayLabel(t *testing.T) {
+	location := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Date(2024, 5, 10, 0, 30, 0, 0, location)
+
+	tests := []struct {
+		releasedAt time.Time
+		expected   string
+	}{
+		{time.Date(2024, 5, 9, 23, 59, 0, 0, location), "Yesterday"},
+		{time.Date(2024, 5, 10, 23, 59, 0, 0, location), "Today"},
+		// 22:30 UTC on the 10th is already the 11th in the local time
+		{time.Date(2024, 5, 10, 22, 30, 0, 0, time.UTC), "Tomorrow"},
+		{time.Date(2024, 5, 12, 12, 0, 0, 0, location), ""},
+		{time.Date(2024, 5, 8, 12, 0, 0, 0, location), ""},
+	}
+
+	for _, test := range tests {
+		if got := relativeDayLabel(now, test.releasedAt, 0); got != test.expected {
+			t.Errorf("%s: expected %q, got %q", test.releasedAt, test.expected, got)
+		}
+	}
+}
+
+func TestSonarrReleasesWithNetworkAirDate(t *testing.T) {
+	plusTwo := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, plusTwo)
+	start, end := getArrReleasesWindow(now)
+
+	// airs at 21:00 in New York on the 10th, which is already the 11th at UTC+2
+	response := sonarrCalendarResponseJson{{SeasonNumber: 1, EpisodeNumber: 1, AirDate: "2024-05-10", AirDateUtc: "2024-05-11T01:00:00Z"}}
+	response[0].Series.Title = "Show"
+	response[0].Series.AirTime = "21:00"
+
+	releases, err := sonarrReleasesFromResponse(&ArrReleaseRequest{Source: ArrSourceSonarr}, response, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(releases) != 0 {
+		t.Fatalf("expected no releases using the UTC air date, got %d", len(releases))
+	}
+
+	releases, err = sonarrReleasesFromResponse(&ArrReleaseRequest{Source: ArrSourceSonarr, AirDateSource: "network"}, response, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(releases) != 1 {
+		t.Fatalf("expected 1 release using the network air date, got %d", len(releases))
+	}
+
+	expected := time.Date(2024, 5, 10, 21, 0, 0, 0, plusTwo)
+
+	if !releases[0].ReleasedAt.Equal(expected) {
+		t.Fatalf("expected release at %v, got %v", expected, releases[0].ReleasedAt)
+	}
+}
+
+func TestArrReleaseRequestFilterWeekdays(t *testing.T) {
+	request := &ArrReleaseRequest{Weekdays: []time.Weekday{time.Friday}, DayStartHour: 4}
+
+	releases := request.filterWeekdays(ArrReleases{
+		{Title: "Friday", ReleasedAt: time.Date(2024, 5, 10, 20, 0, 0, 0, time.UTC)},
+		{Title: "Early Saturday", ReleasedAt: time.Date(2024, 5, 11, 1, 0, 0, 0, time.UTC)},
+		{Title: "Saturday", ReleasedAt: time.Date(2024, 5, 11, 20, 0, 0, 0, time.UTC)},
+	})
+
+	if len(releases) != 2 || releases[0].Title != "Friday" || releases[1].Title != "Early Saturday" {
+		t.Fatalf("expected only the releases counting towards Friday, got %v", releases)
+	}
+}
+
+func TestSonarrReleasesCollapseSeasons(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	start, end := getArrReleasesWindow(now)
+	response := newSonarrTestResponse(t,
+		"2024-05-10T07:00:00Z",
+		"2024-05-10T07:00:00Z",
+		"2024-05-10T07:00:00Z",
+	)
+
+	other := newSonarrTestResponse(t, "2024-05-10T20:00:00Z", "2024-05-10T21:00:00Z")
+	other[0].Series.Title, other[0].Series.TitleSlug = "Other", "other"
+	other[1].Series.Title, other[1].Series.TitleSlug = "Other", "other"
+	response = append(response, other...)
+
+	for i := range response[:3] {
+		response[i].HasFile = i != 1
+	}
+
+	request := &ArrReleaseRequest{Source: ArrSourceSonarr, CollapseSeasons: true}
+	releases, err := sonarrReleasesFromResponse(request, response, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(releases) != 3 {
+		t.Fatalf("expected the season pack and 2 single episodes, got %d releases", len(releases))
+	}
+
+	pack := releases[0]
+
+	if pack.EpisodeCount != 3 || pack.Subtitle != "Season 1 · 3 episodes" {
+		t.Fatalf("expected a pack of 3 episodes, got %d with subtitle %q", pack.EpisodeCount, pack.Subtitle)
+	}
+
+	if pack.Grabbed {
+		t.Fatal("expected the pack not to be grabbed while one of its episodes is missing")
+	}
+
+	if releases[1].EpisodeCount != 0 || releases[2].EpisodeCount != 0 {
+		t.Fatal("expected seasons with fewer episodes on the day not to be collapsed")
+	}
+}
+
+func TestArrReleaseRequestLinkToWithTrailingSlashes(t *testing.T) {
+	for _, base := range []string{"http://localhost:8989", "http://localhost:8989/", "http://localhost:8989//"} {
+		request := &ArrReleaseRequest{URL: "http://unused", LinkBase: base}
+
+		if link := request.linkTo("/series/some-show"); link != "http://localhost:8989/series/some-show" {
+			t.Errorf("expected link without duplicate slashes for base %q, got %q", base, link)
+		}
+	}
+
+	request := &ArrReleaseRequest{URL: "http://localhost:8989/", URLBase: "/sonarr/"}
+
+	if link := request.linkTo("/series/some-show"); link != "http://localhost:8989/sonarr/series/some-show" {
+		t.Errorf("expected link to include the url base once, got %q", link)
+	}
+}
+
+func TestArrReleaseRequestFindImageURL(t *testing.T) {
+	images := []arrImage{
+		{CoverType: "banner", RemoteURL: "banner.jpg"},
+		{CoverType: "poster", RemoteURL: "poster.jpg"},
+	}
+
+	tests := map[string]string{
+		"":       "poster.jpg",
+		"poster": "poster.jpg",
+		"banner": "banner.jpg",
+		// not available, falls back to the poster
+		"fanart": "poster.jpg",
+	}
+
+	for imageType, expected := range tests {
+		request := &ArrReleaseRequest{ImageType: imageType}
+
+		if imageURL := request.findImageURL(images); imageURL != expected {
+			t.Errorf("expected %s for image type %q, got %s", expected, imageType, imageURL)
+		}
+	}
+}
+
+func TestSonarrReleasesFinales(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	start, end := getArrReleasesWindow(now)
+	response := newSonarrTestResponse(t, "2024-05-10T07:00:00Z", "2024-05-10T08:00:00Z")
+	response[1].FinaleType = "season"
+
+	releases, err := sonarrReleasesFromResponse(&ArrReleaseRequest{Source: ArrSourceSonarr}, response, start, end)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(releases) != 2 || releases[0].Finale != "" || releases[1].Finale != "Season Finale" {
+		t.Fatalf("expected only the second episode to be a season finale, got %+v", releases)
+	}
+}
+
+func TestArrReleaseRequestRollingWindow(t *testing.T) {
+	location := time.FixedZone("UTC-5", -5*60*60)
+	now := time.Date(2024, 5, 10, 14, 30, 0, 0, location)
+	request := &ArrReleaseRequest{HoursBehind: 2 * time.Hour, HoursAhead: 12 * time.Hour}
+
+	start, end := request.window(now)
+
+	if got := start.Format(time.RFC3339); got != "2024-05-10T12:30:00-05:00" {
+		t.Errorf("expected start 2024-05-10T12:30:00-05:00, got %s", got)
+	}
+
+	if got := end.Format(time.RFC3339); got != "2024-05-11T02:30:00-05:00" {
+		t.Errorf("expected end 2024-05-11T02:30:00-05:00, got %s", got)
+	}
+
+	tests := []struct {
+		date     string
+		expected bool
+	}{
+		{"2024-05-09T00:00:00Z", false},
+		{"2024-05-10T00:00:00Z", true},
+		{"2024-05-11T00:00:00Z", true},
+		{"2024-05-12T00:00:00Z", false},
+	}
+
+	for _, test := range tests {
+		releasedAt, _ := parseArrReleaseDate(test.date, location, 0)
+
+		if got := isDayWithinWindow(releasedAt, start, end); got != test.expected {
+			t.Errorf("release date %s: expected within window to be %t, got %t", test.date, test.expected, got)
+		}
+	}
+}
+
+func TestArrReleasesSortByReleaseTimeIsStable(t *testing.T) {
+	airedAt := time.Date(2024, 5, 10, 21, 0, 0, 0, time.UTC)
+
+	releases := ArrReleases{
+		{Title: "Some Show", SeasonNumber: 2, EpisodeNumber: 2, ReleasedAt: airedAt},
+		{Title: "Another Show", SeasonNumber: 1, EpisodeNumber: 1, ReleasedAt: airedAt},
+		{Title: "Some Show", SeasonNumber: 2, EpisodeNumber: 1, ReleasedAt: airedAt},
+		{Title: "Earlier Show", ReleasedAt: airedAt.Add(-time.Hour)},
+		{Title: "some show", SeasonNumber: 1, EpisodeNumber: 9, ReleasedAt: airedAt},
+	}
+
+	releases.SortByReleaseTime()
+
+	var got []string
+
+	for _, release := range releases {
+		got = append(got, fmt.Sprintf("%s S%dE%d", release.Title, release.SeasonNumber, release.EpisodeNumber))
+	}
+
+	expected := "Earlier Show S0E0, Another Show S1E1, some show S1E9, Some Show S2E1, Some Show S2E2"
+
+	if joined := strings.Join(got, ", "); joined != expected {
+		t.Errorf("expected %s, got %s", expected, joined)
+	}
+}
+
+func TestResizeArrImageURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		size     int
+		expected string
+	}{
+		{"https://image.tmdb.org/t/p/original/abc.jpg", 342, "https://image.tmdb.org/t/p/w342/abc.jpg"},
+		{"https://image.tmdb.org/t/p/original/abc.jpg", 0, "https://image.tmdb.org/t/p/original/abc.jpg"},
+		{"https://artworks.thetvdb.com/banners/v4/series/1/posters/abc.jpg", 185, "https://artworks.thetvdb.com/banners/v4/series/1/posters/abc_t.jpg"},
+		{"https://artworks.thetvdb.com/banners/posters/81189-10.jpg", 342, "https://artworks.thetvdb.com/banners/_cache/posters/81189-10.jpg"},
+		{"https://artworks.thetvdb.com/banners/posters/81189-10.jpg", 500, "https://artworks.thetvdb.com/banners/posters/81189-10.jpg"},
+		{"https://assets.fanart.tv/fanart/music/abc.jpg", 185, "https://assets.fanart.tv/fanart/music/abc.jpg"},
+		{"", 185, ""},
+	}
+
+	for _, test := range tests {
+		if got := resizeArrImageURL(test.url, test.size); got != test.expected {
+			t.Errorf("resizing %q to %d: expected %q, got %q", test.url, test.size, test.expected, got)
+		}
+	}
+}
+
+func TestArrReleaseRequestEpisodeCode(t *testing.T) {
+	tests := map[string]string{
+		"":       "S01E02",
+		"S01E02": "S01E02",
+		"1x02":   "1x02",
+		"E02":    "E02",
+	}
+
+	for format, expected := range tests {
+		request := &ArrReleaseRequest{EpisodeFormat: format}
+
+		if code := request.episodeCode(1, 2); code != expected {
+			t.Errorf("expected %q for format %q, got %q", expected, format, code)
+		}
+	}
+
+	request := &ArrReleaseRequest{EpisodeFormat: "1x02"}
+
+	if code := request.episodeCode(12, 104); code != "12x104" {
+		t.Errorf("expected numbers wider than the padding to be kept whole, got %q", code)
+	}
+}
@@ -57,7 +57,7 @@ func FetchWatchUUIDsFromChangeDetection(instanceURL string, token string) ([]str
 	return uuids, nil
 }
 
-func FetchWatchesFromChangeDetection(instanceURL string, requestedWatchIDs []string, token string) (ChangeDetectionWatches, error) {
+func FetchWatchesFromChangeDetection(logger *slog.Logger, instanceURL string, requestedWatchIDs []string, token string) (ChangeDetectionWatches, error) {
 	watches := make(ChangeDetectionWatches, 0, len(requestedWatchIDs))
 
 	if len(requestedWatchIDs) == 0 {
@@ -89,7 +89,7 @@ func FetchWatchesFromChangeDetection(instanceURL string, requestedWatchIDs []str
 	for i := range responses {
 		if errs[i] != nil {
 			failed++
-			slog.Error("Failed to fetch or parse change detection watch", "error", errs[i], "url", requests[i].URL)
+			logger.Error("Failed to fetch or parse change detection watch", "error", errs[i], "host", requests[i].URL.Host, "watch", requestedWatchIDs[i])
 			continue
 		}
 
@@ -0,0 +1,254 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type DownloadClientRequest struct {
+	Service       string
+	URL           string
+	APIKey        string
+	Username      string
+	Password      string
+	AllowInsecure bool
+	Limit         int
+}
+
+type Download struct {
+	Name     string
+	Status   string
+	Progress int
+	// zero when the client only reports the speed of the whole queue
+	Speed int64
+	// negative when unknown
+	ETA time.Duration
+}
+
+type DownloadClientStatus struct {
+	Downloads  []Download
+	TotalSpeed int64
+}
+
+func (request *DownloadClientRequest) client() RequestDoer {
+	if request.AllowInsecure {
+		return defaultInsecureClient
+	}
+
+	return defaultClient
+}
+
+type sabnzbdQueueResponseJson struct {
+	Queue struct {
+		KBPerSec string `json:"kbpersec"`
+		Slots    []struct {
+			Filename   string `json:"filename"`
+			Status     string `json:"status"`
+			Percentage string `json:"percentage"`
+			TimeLeft   string `json:"timeleft"`
+		} `json:"slots"`
+	} `json:"queue"`
+}
+
+// time left is formatted as h:mm:ss, or d:hh:mm:ss when over a day
+func parseSabnzbdTimeLeft(timeLeft string) time.Duration {
+	parts := strings.Split(timeLeft, ":")
+	multipliers := []time.Duration{time.Second, time.Minute, time.Hour, 24 * time.Hour}
+
+	if len(parts) < 3 || len(parts) > len(multipliers) {
+		return -1
+	}
+
+	var duration time.Duration
+
+	for i := range parts {
+		value, err := strconv.Atoi(parts[len(parts)-1-i])
+
+		if err != nil {
+			return -1
+		}
+
+		duration += time.Duration(value) * multipliers[i]
+	}
+
+	return duration
+}
+
+func fetchDownloadsFromSabnzbd(ctx context.Context, request *DownloadClientRequest) (*DownloadClientStatus, error) {
+	query := url.Values{}
+	query.Set("mode", "queue")
+	query.Set("output", "json")
+	query.Set("limit", strconv.Itoa(request.Limit))
+	query.Set("apikey", request.APIKey)
+
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", joinURL(request.URL, "api")+"?"+query.Encode(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := decodeJsonFromRequest[sabnzbdQueueResponseJson](request.client(), httpRequest)
+
+	if err != nil {
+		return nil, redactSecretFromError(err, request.APIKey)
+	}
+
+	kbPerSec, _ := strconv.ParseFloat(response.Queue.KBPerSec, 64)
+
+	status := &DownloadClientStatus{
+		Downloads:  make([]Download, 0, len(response.Queue.Slots)),
+		TotalSpeed: int64(kbPerSec * 1024),
+	}
+
+	for i := range response.Queue.Slots {
+		slot := &response.Queue.Slots[i]
+		progress, _ := strconv.Atoi(slot.Percentage)
+
+		status.Downloads = append(status.Downloads, Download{
+			Name:     slot.Filename,
+			Status:   slot.Status,
+			Progress: progress,
+			ETA:      parseSabnzbdTimeLeft(slot.TimeLeft),
+		})
+	}
+
+	return status, nil
+}
+
+type qbittorrentTorrentsResponseJson []struct {
+	Name     string  `json:"name"`
+	State    string  `json:"state"`
+	Progress float64 `json:"progress"`
+	DLSpeed  int64   `json:"dlspeed"`
+	ETA      int64   `json:"eta"`
+}
+
+// qBittorrent reports an ETA of 100 days when it can't estimate one
+const qbittorrentInfiniteETA = 8640000
+
+var qbittorrentStates = map[string]string{
+	"downloading":        "Downloading",
+	"forcedDL":           "Downloading",
+	"metaDL":             "Fetching metadata",
+	"forcedMetaDL":       "Fetching metadata",
+	"stalledDL":          "Stalled",
+	"queuedDL":           "Queued",
+	"pausedDL":           "Paused",
+	"stoppedDL":          "Paused",
+	"checkingDL":         "Checking",
+	"allocating":         "Allocating",
+	"checkingResumeData": "Checking",
+	"moving":             "Moving",
+}
+
+func loginToQbittorrent(ctx context.Context, request *DownloadClientRequest) (*http.Cookie, error) {
+	body := url.Values{}
+	body.Set("username", request.Username)
+	body.Set("password", request.Password)
+
+	httpRequest, err := http.NewRequestWithContext(ctx, "POST", joinURL(request.URL, "api/v2/auth/login"), strings.NewReader(body.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// required by qBittorrent's CSRF protection
+	httpRequest.Header.Set("Referer", joinURL(request.URL))
+
+	response, err := request.client().Do(httpRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d when logging in to qBittorrent", response.StatusCode)
+	}
+
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == "SID" {
+			return cookie, nil
+		}
+	}
+
+	return nil, errors.New("could not log in to qBittorrent, check the username and password")
+}
+
+func fetchDownloadsFromQbittorrent(ctx context.Context, request *DownloadClientRequest) (*DownloadClientStatus, error) {
+	query := url.Values{}
+	query.Set("filter", "downloading")
+	query.Set("sort", "added_on")
+	query.Set("limit", strconv.Itoa(request.Limit))
+
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", joinURL(request.URL, "api/v2/torrents/info")+"?"+query.Encode(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// authentication can be disabled for clients on the local network
+	if request.Username != "" {
+		cookie, err := loginToQbittorrent(ctx, request)
+
+		if err != nil {
+			return nil, err
+		}
+
+		httpRequest.AddCookie(cookie)
+	}
+
+	response, err := decodeJsonFromRequest[qbittorrentTorrentsResponseJson](request.client(), httpRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	status := &DownloadClientStatus{
+		Downloads: make([]Download, 0, len(response)),
+	}
+
+	for i := range response {
+		torrent := &response[i]
+
+		download := Download{
+			Name:     torrent.Name,
+			Status:   qbittorrentStates[torrent.State],
+			Progress: int(torrent.Progress * 100),
+			Speed:    torrent.DLSpeed,
+			ETA:      -1,
+		}
+
+		if download.Status == "" {
+			download.Status = torrent.State
+		}
+
+		if torrent.ETA >= 0 && torrent.ETA < qbittorrentInfiniteETA {
+			download.ETA = time.Duration(torrent.ETA) * time.Second
+		}
+
+		status.TotalSpeed += torrent.DLSpeed
+		status.Downloads = append(status.Downloads, download)
+	}
+
+	return status, nil
+}
+
+func FetchDownloadClientStatus(ctx context.Context, request *DownloadClientRequest) (*DownloadClientStatus, error) {
+	switch request.Service {
+	case "sabnzbd":
+		return fetchDownloadsFromSabnzbd(ctx, request)
+	case "qbittorrent":
+		return fetchDownloadsFromQbittorrent(ctx, request)
+	}
+
+	return nil, fmt.Errorf("unsupported download client '%s'", request.Service)
+}
@@ -50,7 +50,7 @@ func convertExtensionContent(options ExtensionRequestOptions, content []byte, co
 	}
 }
 
-func FetchExtension(options ExtensionRequestOptions) (Extension, error) {
+func FetchExtension(logger *slog.Logger, options ExtensionRequestOptions) (Extension, error) {
 	request, _ := http.NewRequest("GET", options.URL, nil)
 
 	query := url.Values{}
@@ -64,7 +64,7 @@ func FetchExtension(options ExtensionRequestOptions) (Extension, error) {
 	response, err := http.DefaultClient.Do(request)
 
 	if err != nil {
-		slog.Error("failed fetching extension", "error", err, "url", options.URL)
+		logger.Error("failed fetching extension", "error", err, "host", urlHost(options.URL))
 		return Extension{}, fmt.Errorf("%w: request failed: %w", ErrNoContent, err)
 	}
 
@@ -73,7 +73,7 @@ func FetchExtension(options ExtensionRequestOptions) (Extension, error) {
 	body, err := io.ReadAll(response.Body)
 
 	if err != nil {
-		slog.Error("failed reading response body of extension", "error", err, "url", options.URL)
+		logger.Error("failed reading response body of extension", "error", err, "host", urlHost(options.URL))
 		return Extension{}, fmt.Errorf("%w: could not read body: %w", ErrNoContent, err)
 	}
 
@@ -0,0 +1,145 @@
+package feed
+
+import (
+	"context"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// best-effort discovery of the favicons of arbitrary sites, for feeds whose source doesn't
+// provide one. results are cached per host, including not finding any, so that sites
+// without a favicon aren't requested again on every update
+
+const discoveredFaviconsCacheDuration = 24 * time.Hour
+
+// the icon links are in the head, no need to read through the whole page for them
+const faviconDiscoveryMaxBodySize = 512 << 10
+
+var htmlLinkTagPattern = regexp.MustCompile(`(?is)<link\s[^>]*>`)
+var htmlLinkRelPattern = regexp.MustCompile(`(?is)\brel\s*=\s*["']([^"']*)["']`)
+var htmlLinkHrefPattern = regexp.MustCompile(`(?is)\bhref\s*=\s*["']([^"']*)["']`)
+
+type discoveredFavicon struct {
+	url          string
+	discoveredAt time.Time
+}
+
+var discoveredFavicons = make(map[string]discoveredFavicon)
+var discoveredFaviconsMutex sync.Mutex
+
+func discoverFavicon(ctx context.Context, siteURL string) string {
+	parsed, err := url.Parse(siteURL)
+
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return ""
+	}
+
+	discoveredFaviconsMutex.Lock()
+	cached, exists := discoveredFavicons[parsed.Host]
+	discoveredFaviconsMutex.Unlock()
+
+	if exists && time.Since(cached.discoveredAt) < discoveredFaviconsCacheDuration {
+		return cached.url
+	}
+
+	root := &url.URL{Scheme: parsed.Scheme, Host: parsed.Host, Path: "/"}
+	faviconURL := findFaviconInPage(ctx, root)
+
+	if faviconURL == "" {
+		faviconURL = findFaviconAtRoot(ctx, root)
+	}
+
+	// a request that timed out says nothing about whether the site has a favicon
+	if ctx.Err() != nil {
+		return faviconURL
+	}
+
+	discoveredFaviconsMutex.Lock()
+	discoveredFavicons[parsed.Host] = discoveredFavicon{url: faviconURL, discoveredAt: time.Now()}
+	discoveredFaviconsMutex.Unlock()
+
+	return faviconURL
+}
+
+func findFaviconInPage(ctx context.Context, pageURL *url.URL) string {
+	request, err := http.NewRequestWithContext(ctx, "GET", pageURL.String(), nil)
+
+	if err != nil {
+		return ""
+	}
+
+	addBrowserUserAgentHeader(request)
+	response, err := defaultClient.Do(request)
+
+	if err != nil {
+		return ""
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return ""
+	}
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, faviconDiscoveryMaxBodySize))
+
+	if err != nil {
+		return ""
+	}
+
+	for _, tag := range htmlLinkTagPattern.FindAllString(string(body), -1) {
+		rel := htmlLinkRelPattern.FindStringSubmatch(tag)
+
+		// also matches "shortcut icon"
+		if rel == nil || !slices.Contains(strings.Fields(strings.ToLower(rel[1])), "icon") {
+			continue
+		}
+
+		href := htmlLinkHrefPattern.FindStringSubmatch(tag)
+
+		if href == nil {
+			continue
+		}
+
+		// relative to wherever the page ended up after redirects
+		resolved, err := response.Request.URL.Parse(html.UnescapeString(strings.TrimSpace(href[1])))
+
+		if err == nil && (resolved.Scheme == "http" || resolved.Scheme == "https") {
+			return resolved.String()
+		}
+	}
+
+	return ""
+}
+
+func findFaviconAtRoot(ctx context.Context, rootURL *url.URL) string {
+	faviconURL := rootURL.JoinPath("favicon.ico").String()
+	request, err := http.NewRequestWithContext(ctx, "GET", faviconURL, nil)
+
+	if err != nil {
+		return ""
+	}
+
+	addBrowserUserAgentHeader(request)
+	response, err := defaultClient.Do(request)
+
+	if err != nil {
+		return ""
+	}
+
+	response.Body.Close()
+
+	// some sites respond to any path with a page rather than a 404
+	if response.StatusCode != http.StatusOK || strings.HasPrefix(response.Header.Get("Content-Type"), "text/html") {
+		return ""
+	}
+
+	return faviconURL
+}
@@ -0,0 +1,729 @@
+package feed
+
+import (
+	"cmp"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type FreshRssRequest struct {
+	// shown next to the items of the instance when there are several of them
+	Name          string
+	URL           string
+	Username      string
+	APIPassword   string
+	APIPath       string
+	Concurrency   int
+	AllowInsecure bool
+	CACertPath    string
+	// set on every request made to the instance after the default ones
+	Headers    map[string]string
+	IsDetailed bool
+	// fills in the description even when it isn't shown, for filtering by content
+	IncludeDescription bool
+	// items published before this long ago are dropped, 0 keeps all of them
+	MaxAge time.Duration
+	// the most items each feed can contribute, 0 means no limit other than the overall one
+	PerFeedLimit int
+	// looks for the favicons of feeds that FreshRSS doesn't have one for on their sites
+	DiscoverFavicons bool
+	// items published less than this long before they were fetched are marked as new,
+	// 0 doesn't mark any of them
+	HighlightAge time.Duration
+	// items it returns false for are dropped before any of the limits are applied,
+	// nil keeps all of them
+	Filter func(*RSSFeedItem) bool
+}
+
+const freshRssRequestTimeout = 10 * time.Second
+
+// the number of items the Fever API returns per call
+const feverItemsPerPage = 50
+
+// FreshRSS returns IDs as strings while other Fever implementations use numbers
+type feverID int64
+
+func (id *feverID) UnmarshalJSON(data []byte) error {
+	parsed, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+
+	if err != nil {
+		return err
+	}
+
+	*id = feverID(parsed)
+
+	return nil
+}
+
+type feverAuthResponseJson struct {
+	Auth int `json:"auth"`
+}
+
+type feverFeedJson struct {
+	ID        feverID `json:"id"`
+	FaviconID feverID `json:"favicon_id"`
+	Title     string  `json:"title"`
+	SiteURL   string  `json:"site_url"`
+}
+
+type feverFeedsResponseJson struct {
+	feverAuthResponseJson
+	Feeds []feverFeedJson `json:"feeds"`
+}
+
+type feverItemJson struct {
+	ID            feverID `json:"id"`
+	FeedID        feverID `json:"feed_id"`
+	Title         string  `json:"title"`
+	HTML          string  `json:"html"`
+	URL           string  `json:"url"`
+	CreatedOnTime int64   `json:"created_on_time"`
+}
+
+type feverItemsResponseJson struct {
+	feverAuthResponseJson
+	Items []feverItemJson `json:"items"`
+}
+
+type feverFaviconsResponseJson struct {
+	feverAuthResponseJson
+	Favicons []struct {
+		ID   feverID `json:"id"`
+		Data string  `json:"data"`
+	} `json:"favicons"`
+}
+
+type cachedFreshRssFavicons struct {
+	fetchedAt time.Time
+	favicons  map[feverID]template.URL
+}
+
+const freshRssFaviconsCacheDuration = 24 * time.Hour
+
+var freshRssFavicons = make(map[string]*cachedFreshRssFavicons)
+var freshRssFaviconsMutex sync.Mutex
+
+var errFeverUnauthorized = errors.New("invalid username or API password")
+
+func freshRssAPIKey(username, apiPassword string) string {
+	hash := md5.Sum([]byte(username + ":" + apiPassword))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func queryFeverApi[T any](ctx context.Context, request *FreshRssRequest, query string) (T, error) {
+	requestURL := joinURL(request.URL, request.APIPath) + "?api&" + query
+	body := url.Values{"api_key": {freshRssAPIKey(request.Username, request.APIPassword)}}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, "POST", requestURL, strings.NewReader(body.Encode()))
+
+	if err != nil {
+		var result T
+		return result, err
+	}
+
+	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	setRequestHeaders(httpRequest, request.Headers)
+
+	var client RequestDoer = defaultClient
+
+	if request.CACertPath != "" {
+		client, err = getClientWithCACert(request.CACertPath)
+
+		if err != nil {
+			var result T
+			return result, err
+		}
+	} else if request.AllowInsecure {
+		client = defaultInsecureClient
+	}
+
+	return decodeJsonFromRequest[T](client, httpRequest)
+}
+
+// the Fever API returns at most 50 items per call, newest first, so older items are
+// paged through using max_id, starting below maxID unless it's 0. add is called with
+// each item that isn't older than the cutoff and paging stops after the page on which
+// it returns true, the page is kept whole so that the caller can sort by publish date
+// before truncating. returns false if maxPages ran out before that, before the items
+// did or before the cutoff was reached, along with the oldest ID that was seen
+func pageFeverItems(ctx context.Context, request *FreshRssRequest, itemsQuery string, maxID feverID, maxPages int, cutoff time.Time, add func(*feverItemJson) bool) (bool, feverID, error) {
+	query := itemsQuery
+	previousOldestID := maxID
+
+	if maxID != 0 {
+		query += "&max_id=" + strconv.FormatInt(int64(maxID), 10)
+	}
+
+	for page := 0; page < maxPages; page++ {
+		pageCtx, cancel := context.WithTimeout(ctx, freshRssRequestTimeout)
+		response, err := queryFeverApi[feverItemsResponseJson](pageCtx, request, query)
+		cancel()
+
+		if err != nil {
+			return false, previousOldestID, err
+		}
+
+		if response.Auth != 1 {
+			return false, previousOldestID, errFeverUnauthorized
+		}
+
+		if len(response.Items) == 0 {
+			return true, previousOldestID, nil
+		}
+
+		oldestID := response.Items[0].ID
+		var newestCreatedOn int64
+		enough := false
+
+		for i := range response.Items {
+			item := &response.Items[i]
+
+			if item.ID < oldestID {
+				oldestID = item.ID
+			}
+
+			newestCreatedOn = max(newestCreatedOn, item.CreatedOnTime)
+
+			if (cutoff.IsZero() || !time.Unix(item.CreatedOnTime, 0).Before(cutoff)) && add(item) {
+				enough = true
+			}
+		}
+
+		if enough {
+			return true, oldestID, nil
+		}
+
+		// pages are ordered by ID, so there could be newer items further back if
+		// a feed backfilled, but not when a whole page is already past the cutoff
+		if !cutoff.IsZero() && time.Unix(newestCreatedOn, 0).Before(cutoff) {
+			return true, oldestID, nil
+		}
+
+		// guard against implementations that ignore max_id and keep returning the same page
+		if previousOldestID != 0 && oldestID >= previousOldestID {
+			return true, oldestID, nil
+		}
+
+		previousOldestID = oldestID
+		query = itemsQuery + "&max_id=" + strconv.FormatInt(int64(oldestID), 10)
+	}
+
+	return false, previousOldestID, nil
+}
+
+func fetchFeverFeedItems(ctx context.Context, request *FreshRssRequest, feedID feverID, maxID feverID, limit int, cutoff time.Time) ([]feverItemJson, error) {
+	items := make([]feverItemJson, 0, min(limit, feverItemsPerPage))
+	itemsQuery := "items&feed_ids=" + strconv.FormatInt(int64(feedID), 10)
+
+	_, _, err := pageFeverItems(ctx, request, itemsQuery, maxID, math.MaxInt, cutoff, func(item *feverItemJson) bool {
+		items = append(items, *item)
+		return len(items) >= limit
+	})
+
+	return items, err
+}
+
+// favicons rarely change and are returned all at once as base64 encoded data,
+// so they're cached per instance for much longer than the items themselves
+func getFreshRssFavicons(ctx context.Context, request *FreshRssRequest) (map[feverID]template.URL, error) {
+	freshRssFaviconsMutex.Lock()
+	cached, exists := freshRssFavicons[request.URL]
+	freshRssFaviconsMutex.Unlock()
+
+	// the lock isn't held while fetching so that a slow instance doesn't hold up
+	// the others, at worst the favicons of an instance are fetched more than once
+	if exists && time.Since(cached.fetchedAt) < freshRssFaviconsCacheDuration {
+		return cached.favicons, nil
+	}
+
+	response, err := queryFeverApi[feverFaviconsResponseJson](ctx, request, "favicons")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Auth != 1 {
+		return nil, errFeverUnauthorized
+	}
+
+	favicons := make(map[feverID]template.URL, len(response.Favicons))
+
+	for i := range response.Favicons {
+		// data is in the form of image/gif;base64,R0lGODlhAQABAIAAAObm5gAAACH5BAEAAAAALAAAAAABAAEAAAICRAEAOw==
+		if strings.HasPrefix(response.Favicons[i].Data, "image/") {
+			favicons[response.Favicons[i].ID] = template.URL("data:" + response.Favicons[i].Data)
+		}
+	}
+
+	freshRssFaviconsMutex.Lock()
+	freshRssFavicons[request.URL] = &cachedFreshRssFavicons{
+		fetchedAt: time.Now(),
+		favicons:  favicons,
+	}
+	freshRssFaviconsMutex.Unlock()
+
+	return favicons, nil
+}
+
+// turns the items returned by the Fever API into feed items, skipping the ones that were
+// already collected, that don't pass the request's filter or that go over the limit of
+// their feed. The favicons of the feeds are filled in afterwards
+type freshRssItemCollector struct {
+	request        *FreshRssRequest
+	feeds          map[feverID]*feverFeedJson
+	highlightAfter time.Time
+	seen           map[feverID]struct{}
+	itemsByFeed    map[feverID]RSSFeedItems
+	perFeedLimit   int
+	count          int
+	// the number of feeds that have reached perFeedLimit
+	fullFeeds int
+}
+
+func newFreshRssItemCollector(request *FreshRssRequest, feeds []feverFeedJson, perFeedLimit int) *freshRssItemCollector {
+	collector := &freshRssItemCollector{
+		request:      request,
+		perFeedLimit: perFeedLimit,
+		feeds:        make(map[feverID]*feverFeedJson, len(feeds)),
+		seen:         make(map[feverID]struct{}),
+		itemsByFeed:  make(map[feverID]RSSFeedItems),
+	}
+
+	for i := range feeds {
+		collector.feeds[feeds[i].ID] = &feeds[i]
+	}
+
+	if request.HighlightAge > 0 {
+		collector.highlightAfter = time.Now().Add(-request.HighlightAge)
+	}
+
+	return collector
+}
+
+func (collector *freshRssItemCollector) add(feverItem *feverItemJson) {
+	request := collector.request
+	feed, exists := collector.feeds[feverItem.FeedID]
+
+	if !exists {
+		return
+	}
+
+	if _, seen := collector.seen[feverItem.ID]; seen {
+		return
+	}
+
+	collector.seen[feverItem.ID] = struct{}{}
+
+	if len(collector.itemsByFeed[feed.ID]) >= collector.perFeedLimit {
+		return
+	}
+
+	item := RSSFeedItem{
+		ChannelName: feed.Title,
+		ChannelURL:  feed.SiteURL,
+		Title:       feverItem.Title,
+		Link:        feverItem.URL,
+		PublishedAt: time.Unix(feverItem.CreatedOnTime, 0),
+	}
+
+	item.IsNew = request.HighlightAge > 0 && item.PublishedAt.After(collector.highlightAfter)
+
+	if item.Title == "" {
+		item.Title = shortenFeedDescriptionLen(feverItem.HTML, 100)
+	} else if request.IsDetailed || request.IncludeDescription {
+		item.Description = shortenFeedDescriptionLen(feverItem.HTML, 200)
+	}
+
+	if request.Filter != nil && !request.Filter(&item) {
+		return
+	}
+
+	collector.itemsByFeed[feed.ID] = append(collector.itemsByFeed[feed.ID], item)
+	collector.count++
+
+	if len(collector.itemsByFeed[feed.ID]) == collector.perFeedLimit {
+		collector.fullFeeds++
+	}
+}
+
+func (collector *freshRssItemCollector) items(ctx context.Context, favicons map[feverID]template.URL) RSSFeedItems {
+	request := collector.request
+	iconURLs := make(map[feverID]template.URL, len(collector.itemsByFeed))
+	var undiscovered []*feverFeedJson
+
+	for feedID := range collector.itemsByFeed {
+		feed := collector.feeds[feedID]
+		iconURLs[feedID] = favicons[feed.FaviconID]
+
+		if iconURLs[feedID] == "" && request.DiscoverFavicons {
+			undiscovered = append(undiscovered, feed)
+		}
+	}
+
+	if len(undiscovered) > 0 {
+		job := newJob(func(feed *feverFeedJson) (template.URL, error) {
+			ctx, cancel := context.WithTimeout(ctx, freshRssRequestTimeout)
+			defer cancel()
+
+			return template.URL(discoverFavicon(ctx, feed.SiteURL)), nil
+		}, undiscovered).withWorkers(request.Concurrency)
+
+		if discovered, _, err := workerPoolDo(job); err == nil {
+			for i := range undiscovered {
+				iconURLs[undiscovered[i].ID] = discovered[i]
+			}
+		}
+	}
+
+	items := make(RSSFeedItems, 0, collector.count)
+
+	for feedID, feedItems := range collector.itemsByFeed {
+		for i := range feedItems {
+			feedItems[i].ChannelIconURL = iconURLs[feedID]
+		}
+
+		items = append(items, feedItems...)
+	}
+
+	return items
+}
+
+// the most pages of the items of all feeds that are requested before falling back to
+// requesting the items of each feed that's still short on its own
+const freshRssMaxStreamPages = 10
+
+// returns the number of feeds that failed alongside the items of those that didn't,
+// a limit of -1 returns all of the items that were fetched. The items of all feeds
+// are paged through together so that the number of requests doesn't grow with the
+// number of feeds, only the feeds that still don't have enough items once the pages
+// run out are requested one by one
+func GetItemsFromFreshRssFeeds(ctx context.Context, logger *slog.Logger, request *FreshRssRequest, limit int) (RSSFeedItems, int, error) {
+	feedsResponse, err := queryFeverApi[feverFeedsResponseJson](ctx, request, "feeds")
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if feedsResponse.Auth != 1 {
+		return nil, 0, errFeverUnauthorized
+	}
+
+	feeds := feedsResponse.Feeds
+
+	if len(feeds) == 0 {
+		return RSSFeedItems{}, 0, nil
+	}
+
+	favicons, err := getFreshRssFavicons(ctx, request)
+
+	if err != nil {
+		logger.Warn("Failed to fetch FreshRSS favicons", "error", err, "host", urlHost(request.URL))
+	}
+
+	var cutoff time.Time
+
+	if request.MaxAge > 0 {
+		cutoff = time.Now().Add(-request.MaxAge)
+	}
+
+	// without an overall limit, feeds are paged through until either their own
+	// limit or the max age is reached, otherwise until the overall limit is
+	perFeedLimit := limit
+
+	if limit < 0 {
+		if request.PerFeedLimit > 0 {
+			perFeedLimit = request.PerFeedLimit
+		} else if request.MaxAge > 0 {
+			perFeedLimit = math.MaxInt
+		} else {
+			perFeedLimit = feverItemsPerPage
+		}
+	}
+
+	if request.PerFeedLimit > 0 && request.PerFeedLimit < perFeedLimit {
+		perFeedLimit = request.PerFeedLimit
+	}
+
+	collector := newFreshRssItemCollector(request, feeds, perFeedLimit)
+
+	complete, oldestID, err := pageFeverItems(ctx, request, "items", 0, freshRssMaxStreamPages, cutoff, func(item *feverItemJson) bool {
+		collector.add(item)
+		return (limit >= 0 && collector.count >= limit) || collector.fullFeeds == len(feeds)
+	})
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	failed := 0
+
+	if !complete {
+		var shortFeeds []feverFeedJson
+
+		for i := range feeds {
+			if len(collector.itemsByFeed[feeds[i].ID]) < perFeedLimit {
+				shortFeeds = append(shortFeeds, feeds[i])
+			}
+		}
+
+		// only the items older than the ones that were already seen are requested
+		job := newJob(func(feed feverFeedJson) ([]feverItemJson, error) {
+			return fetchFeverFeedItems(ctx, request, feed.ID, oldestID, perFeedLimit-len(collector.itemsByFeed[feed.ID]), cutoff)
+		}, shortFeeds).withWorkers(request.Concurrency)
+
+		results, errs, err := workerPoolDo(job)
+
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: %v", ErrNoContent, err)
+		}
+
+		for i := range results {
+			if errs[i] != nil {
+				failed++
+				logger.Error("Failed to fetch FreshRSS feed", "error", errs[i], "host", urlHost(request.URL), "feed", shortFeeds[i].Title)
+				continue
+			}
+
+			for j := range results[i] {
+				collector.add(&results[i][j])
+			}
+		}
+	}
+
+	items := collector.items(ctx, favicons)
+
+	// IDs reflect the order in which FreshRSS fetched the items rather than when they were published
+	items.SortByNewest()
+
+	if limit >= 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	if failed > 0 {
+		return items, failed, fmt.Errorf("%w: missing %d FreshRSS feeds", ErrPartialContent, failed)
+	}
+
+	return items, 0, nil
+}
+
+type freshRssInstanceItems struct {
+	items  RSSFeedItems
+	failed int
+}
+
+func fetchFreshRssInstanceItemsTask(ctx context.Context, logger *slog.Logger, limit int) func(*FreshRssRequest) (freshRssInstanceItems, error) {
+	return func(request *FreshRssRequest) (freshRssInstanceItems, error) {
+		items, failed, err := GetItemsFromFreshRssFeeds(ctx, logger, request, limit)
+
+		if err != nil && !errors.Is(err, ErrPartialContent) {
+			return freshRssInstanceItems{}, err
+		}
+
+		for i := range items {
+			items[i].SourceName = request.Name
+		}
+
+		return freshRssInstanceItems{items: items, failed: failed}, nil
+	}
+}
+
+// merges the items of several instances, or of several users of the same one, in the
+// same way as GetItemsFromFreshRssFeeds does for feeds. The same article showing up in
+// more than one of them is only kept once, from whichever instance comes first
+func GetItemsFromFreshRssInstances(ctx context.Context, logger *slog.Logger, requests []*FreshRssRequest, limit int) (RSSFeedItems, int, error) {
+	job := newJob(fetchFreshRssInstanceItemsTask(ctx, logger, limit), requests).withWorkers(len(requests))
+	results, errs, err := workerPoolDo(job)
+
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrNoContent, err)
+	}
+
+	var failedInstances []string
+	failedFeeds := 0
+	seenLinks := make(map[string]struct{})
+	items := make(RSSFeedItems, 0, len(requests)*25)
+
+	for i := range results {
+		if errs[i] != nil {
+			failedInstances = append(failedInstances, cmp.Or(requests[i].Name, urlHost(requests[i].URL)))
+			logger.Error("Failed to fetch FreshRSS items", "error", errs[i], "host", urlHost(requests[i].URL), "username", requests[i].Username)
+			continue
+		}
+
+		failedFeeds += results[i].failed
+
+		for _, item := range results[i].items {
+			if _, seen := seenLinks[item.Link]; seen && item.Link != "" {
+				continue
+			}
+
+			seenLinks[item.Link] = struct{}{}
+			items = append(items, item)
+		}
+	}
+
+	if len(failedInstances) == len(requests) {
+		return nil, failedFeeds, fmt.Errorf("%w: %v", ErrNoContent, errs[0])
+	}
+
+	items.SortByNewest()
+
+	if limit >= 0 && len(items) > limit {
+		items = items[:limit]
+	}
+
+	if len(failedInstances) > 0 {
+		return items, failedFeeds, fmt.Errorf("%w: could not reach %s", ErrPartialContent, strings.Join(failedInstances, ", "))
+	}
+
+	if failedFeeds > 0 {
+		return items, failedFeeds, fmt.Errorf("%w: missing %d FreshRSS feeds", ErrPartialContent, failedFeeds)
+	}
+
+	return items, 0, nil
+}
+
+type feverUnreadItemIDsResponseJson struct {
+	feverAuthResponseJson
+	// comma separated
+	UnreadItemIDs string `json:"unread_item_ids"`
+}
+
+type feverGroupsResponseJson struct {
+	feverAuthResponseJson
+	Groups []struct {
+		ID    feverID `json:"id"`
+		Title string  `json:"title"`
+	} `json:"groups"`
+	FeedsGroups []struct {
+		GroupID feverID `json:"group_id"`
+		// comma separated
+		FeedIDs string `json:"feed_ids"`
+	} `json:"feeds_groups"`
+}
+
+type FreshRssUnreadGroup struct {
+	Name  string
+	Count int
+}
+
+type FreshRssUnreadCount struct {
+	Total  int
+	Groups []FreshRssUnreadGroup
+}
+
+func splitFeverIDs(ids string) []string {
+	split := strings.Split(ids, ",")
+
+	return slices.DeleteFunc(split, func(id string) bool {
+		return strings.TrimSpace(id) == ""
+	})
+}
+
+// the Fever API only lists the IDs of unread items without the feeds they belong to,
+// so counting them per group means fetching the items themselves, 50 at a time
+func FetchFreshRssUnreadCount(ctx context.Context, request *FreshRssRequest, perGroup bool) (*FreshRssUnreadCount, error) {
+	response, err := queryFeverApi[feverUnreadItemIDsResponseJson](ctx, request, "unread_item_ids")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Auth != 1 {
+		return nil, errFeverUnauthorized
+	}
+
+	unreadIDs := splitFeverIDs(response.UnreadItemIDs)
+	count := &FreshRssUnreadCount{Total: len(unreadIDs)}
+
+	if !perGroup || len(unreadIDs) == 0 {
+		return count, nil
+	}
+
+	groupsResponse, err := queryFeverApi[feverGroupsResponseJson](ctx, request, "groups")
+
+	if err == nil && groupsResponse.Auth != 1 {
+		err = errFeverUnauthorized
+	}
+
+	if err != nil {
+		return count, fmt.Errorf("%w: could not fetch groups: %v", ErrPartialContent, err)
+	}
+
+	batches := make([][]string, 0, len(unreadIDs)/feverItemsPerPage+1)
+
+	for start := 0; start < len(unreadIDs); start += feverItemsPerPage {
+		batches = append(batches, unreadIDs[start:min(start+feverItemsPerPage, len(unreadIDs))])
+	}
+
+	job := newJob(func(ids []string) ([]feverItemJson, error) {
+		response, err := queryFeverApi[feverItemsResponseJson](ctx, request, "items&with_ids="+strings.Join(ids, ","))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if response.Auth != 1 {
+			return nil, errFeverUnauthorized
+		}
+
+		return response.Items, nil
+	}, batches).withWorkers(request.Concurrency)
+
+	results, errs, err := workerPoolDo(job)
+
+	if err != nil {
+		return count, fmt.Errorf("%w: %v", ErrPartialContent, err)
+	}
+
+	unreadPerFeed := make(map[feverID]int)
+
+	for i := range results {
+		if errs[i] != nil {
+			return count, fmt.Errorf("%w: could not fetch unread items: %v", ErrPartialContent, errs[i])
+		}
+
+		for j := range results[i] {
+			unreadPerFeed[results[i][j].FeedID]++
+		}
+	}
+
+	unreadPerGroup := make(map[feverID]int, len(groupsResponse.Groups))
+
+	for _, feedsGroup := range groupsResponse.FeedsGroups {
+		for _, id := range splitFeverIDs(feedsGroup.FeedIDs) {
+			feedID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+
+			if err == nil {
+				unreadPerGroup[feedsGroup.GroupID] += unreadPerFeed[feverID(feedID)]
+			}
+		}
+	}
+
+	count.Groups = make([]FreshRssUnreadGroup, 0, len(groupsResponse.Groups))
+
+	for _, group := range groupsResponse.Groups {
+		if unreadPerGroup[group.ID] > 0 {
+			count.Groups = append(count.Groups, FreshRssUnreadGroup{
+				Name:  group.Title,
+				Count: unreadPerGroup[group.ID],
+			})
+		}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,143 @@
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// serves feeds 1 and 2 with an item each for IDs 1000 down to 401 and feed 3
+// with only IDs 3 down to 1, so that its items are past the pages of all feeds
+func newMockFeverServer() (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var itemQueries []string
+
+	now := time.Now().Unix()
+	items := make([]feverItemJson, 0, 603)
+
+	for id := 1000; id > 400; id-- {
+		items = append(items, feverItemJson{ID: feverID(id), FeedID: feverID(id%2 + 1), Title: "Item " + strconv.Itoa(id), CreatedOnTime: now - int64(1000-id)})
+	}
+
+	for id := 3; id > 0; id-- {
+		items = append(items, feverItemJson{ID: feverID(id), FeedID: 3, Title: "Item " + strconv.Itoa(id), CreatedOnTime: now - 5000})
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case query.Has("feeds"):
+			io.WriteString(w, `{"auth": 1, "feeds": [
+				{"id": 1, "title": "One"},
+				{"id": 2, "title": "Two"},
+				{"id": "3", "title": "Three"}
+			]}`)
+		case query.Has("favicons"):
+			io.WriteString(w, `{"auth": 1, "favicons": []}`)
+		case query.Has("items"):
+			mu.Lock()
+			itemQueries = append(itemQueries, r.URL.RawQuery)
+			mu.Unlock()
+
+			maxID := int64(1 << 62)
+
+			if query.Has("max_id") {
+				maxID, _ = strconv.ParseInt(query.Get("max_id"), 10, 64)
+			}
+
+			page := make([]map[string]any, 0, feverItemsPerPage)
+
+			for _, item := range items {
+				if int64(item.ID) >= maxID || (query.Has("feed_ids") && strconv.FormatInt(int64(item.FeedID), 10) != query.Get("feed_ids")) {
+					continue
+				}
+
+				page = append(page, map[string]any{
+					"id":              item.ID,
+					"feed_id":         item.FeedID,
+					"title":           item.Title,
+					"created_on_time": item.CreatedOnTime,
+				})
+
+				if len(page) == feverItemsPerPage {
+					break
+				}
+			}
+
+			json.NewEncoder(w).Encode(map[string]any{"auth": 1, "items": page})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]string(nil), itemQueries...)
+	}
+}
+
+func TestFreshRssItemsArePagedTogether(t *testing.T) {
+	server, itemQueries := newMockFeverServer()
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	items, failed, err := GetItemsFromFreshRssFeeds(context.Background(), logger, &FreshRssRequest{URL: server.URL, Concurrency: 2}, 60)
+
+	if err != nil || failed != 0 {
+		t.Fatalf("expected no error, got %v with %d failed feeds", err, failed)
+	}
+
+	if len(items) != 60 || items[0].Title != "Item 1000" {
+		t.Errorf("expected the newest 60 items starting with Item 1000, got %d items", len(items))
+	}
+
+	if queries := itemQueries(); len(queries) != 2 {
+		t.Errorf("expected 2 pages of the items of all feeds to be requested, got %q", queries)
+	}
+}
+
+func TestFreshRssFallsBackToFeedsThatAreShort(t *testing.T) {
+	server, itemQueries := newMockFeverServer()
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	request := &FreshRssRequest{URL: server.URL, Concurrency: 2, PerFeedLimit: 5}
+
+	items, failed, err := GetItemsFromFreshRssFeeds(context.Background(), logger, request, -1)
+
+	if err != nil || failed != 0 {
+		t.Fatalf("expected no error, got %v with %d failed feeds", err, failed)
+	}
+
+	countByFeed := make(map[string]int)
+
+	for i := range items {
+		countByFeed[items[i].ChannelName]++
+	}
+
+	if countByFeed["One"] != 5 || countByFeed["Two"] != 5 || countByFeed["Three"] != 3 {
+		t.Errorf("expected 5, 5 and 3 items from the feeds, got %v", countByFeed)
+	}
+
+	queries := itemQueries()
+
+	if len(queries) != freshRssMaxStreamPages+2 {
+		t.Fatalf("expected %d pages of all feeds and 2 of feed 3, got %q", freshRssMaxStreamPages, queries)
+	}
+
+	if fallback := queries[freshRssMaxStreamPages]; fallback != "api&items&feed_ids=3&max_id=501" {
+		t.Errorf("expected feed 3 to be requested below the oldest item seen, got %q", fallback)
+	}
+}
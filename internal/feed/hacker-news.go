@@ -29,7 +29,7 @@ func getHackerNewsPostIds(sort string) ([]int, error) {
 	return response, nil
 }
 
-func getHackerNewsPostsFromIds(postIds []int, commentsUrlTemplate string) (ForumPosts, error) {
+func getHackerNewsPostsFromIds(logger *slog.Logger, postIds []int, commentsUrlTemplate string) (ForumPosts, error) {
 	requests := make([]*http.Request, len(postIds))
 
 	for i, id := range postIds {
@@ -49,7 +49,7 @@ func getHackerNewsPostsFromIds(postIds []int, commentsUrlTemplate string) (Forum
 
 	for i := range results {
 		if errs[i] != nil {
-			slog.Error("Failed to fetch or parse hacker news post", "error", errs[i], "url", requests[i].URL)
+			logger.Error("Failed to fetch or parse hacker news post", "error", errs[i], "host", requests[i].URL.Host, "post", postIds[i])
 			continue
 		}
 
@@ -83,7 +83,7 @@ func getHackerNewsPostsFromIds(postIds []int, commentsUrlTemplate string) (Forum
 	return posts, nil
 }
 
-func FetchHackerNewsPosts(sort string, limit int, commentsUrlTemplate string) (ForumPosts, error) {
+func FetchHackerNewsPosts(logger *slog.Logger, sort string, limit int, commentsUrlTemplate string) (ForumPosts, error) {
 	postIds, err := getHackerNewsPostIds(sort)
 
 	if err != nil {
@@ -94,5 +94,5 @@ func FetchHackerNewsPosts(sort string, limit int, commentsUrlTemplate string) (F
 		postIds = postIds[:limit]
 	}
 
-	return getHackerNewsPostsFromIds(postIds, commentsUrlTemplate)
+	return getHackerNewsPostsFromIds(logger, postIds, commentsUrlTemplate)
 }
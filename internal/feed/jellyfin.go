@@ -0,0 +1,237 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type JellyfinRequest struct {
+	URL           string
+	APIKey        string
+	UserID        string
+	AllowInsecure bool
+	Limit         int
+}
+
+type JellyfinItem struct {
+	Title    string
+	Subtitle string
+	URL      string
+	ImageURL string
+	AddedAt  time.Time
+}
+
+type JellyfinItems []JellyfinItem
+
+type jellyfinLatestResponseJson []struct {
+	ID                    string `json:"Id"`
+	Name                  string `json:"Name"`
+	Type                  string `json:"Type"`
+	ProductionYear        int    `json:"ProductionYear"`
+	SeriesID              string `json:"SeriesId"`
+	SeriesName            string `json:"SeriesName"`
+	SeriesPrimaryImageTag string `json:"SeriesPrimaryImageTag"`
+	ParentIndexNumber     int    `json:"ParentIndexNumber"`
+	IndexNumber           int    `json:"IndexNumber"`
+	DateCreated           string `json:"DateCreated"`
+	ImageTags             struct {
+		Primary string `json:"Primary"`
+	} `json:"ImageTags"`
+}
+
+func jellyfinImageURL(baseURL, itemID, tag string) string {
+	if tag == "" {
+		return ""
+	}
+
+	return joinURL(baseURL, "Items", itemID, "Images/Primary") + "?fillHeight=300&quality=90&tag=" + url.QueryEscape(tag)
+}
+
+func FetchJellyfinRecentlyAdded(ctx context.Context, request *JellyfinRequest) (JellyfinItems, error) {
+	query := url.Values{}
+	query.Set("userId", request.UserID)
+	query.Set("limit", strconv.Itoa(request.Limit))
+	query.Set("fields", "DateCreated")
+	query.Set("includeItemTypes", "Movie,Episode")
+	// by default episodes of the same series are grouped together into a single item
+	query.Set("groupItems", "false")
+
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", joinURL(request.URL, "Items/Latest")+"?"+query.Encode(), nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpRequest.Header.Set("X-Emby-Token", request.APIKey)
+
+	var client RequestDoer = defaultClient
+
+	if request.AllowInsecure {
+		client = defaultInsecureClient
+	}
+
+	response, err := decodeJsonFromRequest[jellyfinLatestResponseJson](client, httpRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	items := make(JellyfinItems, 0, len(response))
+
+	for i := range response {
+		responseItem := &response[i]
+
+		item := JellyfinItem{
+			URL: joinURL(request.URL, "web/#/details?id="+responseItem.ID),
+		}
+
+		if addedAt, err := time.Parse(time.RFC3339Nano, responseItem.DateCreated); err == nil {
+			item.AddedAt = addedAt
+		}
+
+		if responseItem.Type == "Episode" {
+			item.Title = responseItem.SeriesName
+			item.Subtitle = fmt.Sprintf("S%02dE%02d · %s", responseItem.ParentIndexNumber, responseItem.IndexNumber, responseItem.Name)
+			item.ImageURL = jellyfinImageURL(request.URL, responseItem.SeriesID, responseItem.SeriesPrimaryImageTag)
+		} else {
+			item.Title = responseItem.Name
+			item.ImageURL = jellyfinImageURL(request.URL, responseItem.ID, responseItem.ImageTags.Primary)
+
+			if responseItem.ProductionYear > 0 {
+				item.Subtitle = strconv.Itoa(responseItem.ProductionYear)
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+type jellyfinItemsResponseJson struct {
+	Items []struct {
+		ID                string            `json:"Id"`
+		SeriesID          string            `json:"SeriesId"`
+		ParentIndexNumber int               `json:"ParentIndexNumber"`
+		IndexNumber       int               `json:"IndexNumber"`
+		ProviderIDs       map[string]string `json:"ProviderIds"`
+	} `json:"Items"`
+}
+
+// the episodes that the user has played, keyed by the TheTVDB IDs of the episode
+// and of its series, which both Sonarr and Jellyfin know about
+type JellyfinPlayedEpisodes struct {
+	episodes       map[int]struct{}
+	seriesEpisodes map[string]struct{}
+}
+
+func jellyfinSeriesEpisodeKey(seriesTVDbID, season, episode int) string {
+	return fmt.Sprintf("%d:%d:%d", seriesTVDbID, season, episode)
+}
+
+// episodes are matched by their own ID first, falling back to their number within the
+// series since Jellyfin only has the former when its metadata came from TheTVDB
+func (p *JellyfinPlayedEpisodes) Contains(tvdbID, seriesTVDbID, season, episode int) bool {
+	if _, exists := p.episodes[tvdbID]; exists && tvdbID > 0 {
+		return true
+	}
+
+	if seriesTVDbID <= 0 {
+		return false
+	}
+
+	_, exists := p.seriesEpisodes[jellyfinSeriesEpisodeKey(seriesTVDbID, season, episode)]
+	return exists
+}
+
+func queryJellyfinItems(ctx context.Context, request *JellyfinRequest, query url.Values) (jellyfinItemsResponseJson, error) {
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", joinURL(request.URL, "Items")+"?"+query.Encode(), nil)
+
+	if err != nil {
+		return jellyfinItemsResponseJson{}, err
+	}
+
+	httpRequest.Header.Set("X-Emby-Token", request.APIKey)
+
+	var client RequestDoer = defaultClient
+
+	if request.AllowInsecure {
+		client = defaultInsecureClient
+	}
+
+	return decodeJsonFromRequest[jellyfinItemsResponseJson](client, httpRequest)
+}
+
+// only the provider IDs of the episodes are returned, so the ones of their series
+// are requested separately for the episodes to be matched by their numbers
+func FetchJellyfinPlayedEpisodes(ctx context.Context, request *JellyfinRequest, since time.Time) (*JellyfinPlayedEpisodes, error) {
+	query := url.Values{}
+	query.Set("userId", request.UserID)
+	query.Set("recursive", "true")
+	query.Set("includeItemTypes", "Episode")
+	query.Set("isPlayed", "true")
+	query.Set("fields", "ProviderIds")
+	query.Set("minPremiereDate", since.UTC().Format(time.RFC3339))
+
+	episodes, err := queryJellyfinItems(ctx, request, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	played := &JellyfinPlayedEpisodes{
+		episodes:       make(map[int]struct{}, len(episodes.Items)),
+		seriesEpisodes: make(map[string]struct{}, len(episodes.Items)),
+	}
+
+	if len(episodes.Items) == 0 {
+		return played, nil
+	}
+
+	seriesIDs := make([]string, 0, len(episodes.Items))
+
+	for i := range episodes.Items {
+		if id := episodes.Items[i].SeriesID; id != "" && !slices.Contains(seriesIDs, id) {
+			seriesIDs = append(seriesIDs, id)
+		}
+	}
+
+	query = url.Values{}
+	query.Set("userId", request.UserID)
+	query.Set("ids", strings.Join(seriesIDs, ","))
+	query.Set("fields", "ProviderIds")
+
+	series, err := queryJellyfinItems(ctx, request, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	seriesTVDbIDs := make(map[string]int, len(series.Items))
+
+	for i := range series.Items {
+		if id, err := strconv.Atoi(series.Items[i].ProviderIDs["Tvdb"]); err == nil {
+			seriesTVDbIDs[series.Items[i].ID] = id
+		}
+	}
+
+	for i := range episodes.Items {
+		episode := &episodes.Items[i]
+
+		if id, err := strconv.Atoi(episode.ProviderIDs["Tvdb"]); err == nil {
+			played.episodes[id] = struct{}{}
+		}
+
+		if seriesID, exists := seriesTVDbIDs[episode.SeriesID]; exists {
+			played.seriesEpisodes[jellyfinSeriesEpisodeKey(seriesID, episode.ParentIndexNumber, episode.IndexNumber)] = struct{}{}
+		}
+	}
+
+	return played, nil
+}
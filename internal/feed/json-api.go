@@ -0,0 +1,145 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type JSONAPIRequest struct {
+	URL           string
+	Headers       map[string]string
+	AllowInsecure bool
+	Timeout       time.Duration
+	// path to the array within the response whose elements become the items
+	ItemsPath string
+	// maps the names available in the template to paths within each item
+	Fields map[string]string
+}
+
+type JSONAPIResponse struct {
+	// the whole decoded response
+	Data any
+	// the elements of the array at ItemsPath, as maps of the configured fields
+	// when there are any or as they were in the response otherwise
+	Items []any
+}
+
+// paths are made up of object keys and array indexes separated by dots, e.g.
+// data.results.0.name, an empty path refers to the value itself
+func lookupJSONPath(value any, path string) (any, bool) {
+	if path == "" {
+		return value, true
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			next, exists := v[key]
+
+			if !exists {
+				return nil, false
+			}
+
+			value = next
+		case []any:
+			index, err := strconv.Atoi(key)
+
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+
+			value = v[index]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+func (request *JSONAPIRequest) client() RequestDoer {
+	if request.Timeout == 0 || request.Timeout == defaultClientTimeout {
+		if request.AllowInsecure {
+			return defaultInsecureClient
+		}
+
+		return defaultClient
+	}
+
+	client := &http.Client{Timeout: request.Timeout}
+
+	if request.AllowInsecure {
+		client.Transport = insecureClientTransport
+	}
+
+	return client
+}
+
+func FetchJSONAPI(ctx context.Context, request *JSONAPIRequest) (*JSONAPIResponse, error) {
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", request.URL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	setRequestHeaders(httpRequest, request.Headers)
+
+	// the defaults are only filled in so that the configured headers can replace them
+	if httpRequest.Header.Get("Accept") == "" {
+		httpRequest.Header.Set("Accept", "application/json")
+	}
+
+	if httpRequest.Header.Get("User-Agent") == "" {
+		httpRequest.Header.Set("User-Agent", GlanceUserAgent)
+	}
+
+	data, err := decodeJsonFromRequest[any](request.client(), httpRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := &JSONAPIResponse{Data: data}
+
+	if request.ItemsPath == "" && len(request.Fields) == 0 {
+		response.Items, _ = data.([]any)
+		return response, nil
+	}
+
+	value, found := lookupJSONPath(data, request.ItemsPath)
+
+	if !found {
+		return nil, fmt.Errorf("nothing found at items path '%s'", request.ItemsPath)
+	}
+
+	elements, ok := value.([]any)
+
+	if !ok {
+		return nil, fmt.Errorf("expected an array at items path '%s', got %T", request.ItemsPath, value)
+	}
+
+	response.Items = make([]any, 0, len(elements))
+
+	for _, element := range elements {
+		if len(request.Fields) == 0 {
+			response.Items = append(response.Items, element)
+			continue
+		}
+
+		// missing fields are left empty rather than failing the whole widget
+		// since APIs commonly omit keys without a value
+		item := make(map[string]any, len(request.Fields))
+
+		for name, path := range request.Fields {
+			item[name], _ = lookupJSONPath(element, path)
+		}
+
+		response.Items = append(response.Items, item)
+	}
+
+	return response, nil
+}
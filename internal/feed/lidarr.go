@@ -0,0 +1,75 @@
+package feed
+
+import (
+	"context"
+	"time"
+)
+
+type lidarrCalendarResponseJson []struct {
+	Title          string     `json:"title"`
+	Overview       string     `json:"overview"`
+	AlbumType      string     `json:"albumType"`
+	ReleaseDate    string     `json:"releaseDate"`
+	ForeignAlbumID string     `json:"foreignAlbumId"`
+	Images         []arrImage `json:"images"`
+	Artist         struct {
+		ArtistName string     `json:"artistName"`
+		Images     []arrImage `json:"images"`
+	} `json:"artist"`
+	Statistics struct {
+		TrackFileCount int `json:"trackFileCount"`
+		TrackCount     int `json:"trackCount"`
+	} `json:"statistics"`
+}
+
+func fetchReleasesFromLidarr(ctx context.Context, request *ArrReleaseRequest, start, end time.Time) (ArrReleases, error) {
+	query := arrCalendarQuery(start, end)
+	query.Set("includeArtist", "true")
+
+	response, err := queryArrCalendarApi[lidarrCalendarResponseJson](ctx, request, "/api/v1/calendar", request.withExtraQuery(query))
+
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make(ArrReleases, 0, len(response))
+
+	for i := range response {
+		album := &response[i]
+
+		releasedAt, ok := parseArrReleaseDate(album.ReleaseDate, start.Location(), request.DayStartHour)
+
+		if !ok || !isDayWithinWindow(releasedAt, start, end) {
+			continue
+		}
+
+		var imageURL string
+
+		// albums only have covers, the landscape images are the artist's
+		if request.ImageType == "fanart" || request.ImageType == "banner" {
+			imageURL = findArrImageURL(album.Artist.Images, request.ImageType)
+		}
+
+		if imageURL == "" {
+			imageURL = findArrImageURL(album.Images, "cover")
+		}
+
+		if imageURL == "" {
+			imageURL = findArrImageURL(album.Artist.Images, "poster")
+		}
+
+		releases = append(releases, ArrRelease{
+			Source:      ArrSourceLidarr,
+			Title:       album.Artist.ArtistName,
+			Subtitle:    album.Title,
+			Overview:    request.shortenOverview(album.Overview),
+			URL:         request.linkTo("/album/" + album.ForeignAlbumID),
+			ImageURL:    request.posterURL(imageURL),
+			ReleaseType: album.AlbumType,
+			ReleasedAt:  releasedAt,
+			Grabbed:     album.Statistics.TrackCount > 0 && album.Statistics.TrackFileCount >= album.Statistics.TrackCount,
+		})
+	}
+
+	return releases, nil
+}
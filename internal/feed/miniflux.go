@@ -0,0 +1,215 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type MinifluxRequest struct {
+	URL           string
+	APIToken      string
+	AllowInsecure bool
+	CACertPath    string
+	// set on every request made to the instance after the default ones
+	Headers map[string]string
+	// unread, read or empty for both
+	Status     string
+	IsDetailed bool
+	// items published before this long ago are dropped, 0 keeps all of them
+	MaxAge time.Duration
+}
+
+type minifluxEntryJson struct {
+	ID          int64     `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Content     string    `json:"content"`
+	PublishedAt time.Time `json:"published_at"`
+	Feed        struct {
+		ID      int64  `json:"id"`
+		Title   string `json:"title"`
+		SiteURL string `json:"site_url"`
+		Icon    *struct {
+			IconID int64 `json:"icon_id"`
+		} `json:"icon"`
+	} `json:"feed"`
+}
+
+type minifluxEntriesResponseJson struct {
+	Total   int                 `json:"total"`
+	Entries []minifluxEntryJson `json:"entries"`
+}
+
+type minifluxIconResponseJson struct {
+	ID int64 `json:"id"`
+	// in the form of image/png;base64,iVBORw0KGgo...
+	Data string `json:"data"`
+}
+
+const minifluxIconsCacheDuration = 24 * time.Hour
+
+type cachedMinifluxIcon struct {
+	fetchedAt time.Time
+	url       template.URL
+}
+
+// icons are keyed by the instance's URL followed by the icon's ID since
+// they never change for a given ID, unlike the feed they belong to
+var minifluxIcons = make(map[string]*cachedMinifluxIcon)
+var minifluxIconsMutex sync.Mutex
+
+func queryMinifluxApi[T any](ctx context.Context, request *MinifluxRequest, path string, query url.Values) (T, error) {
+	requestURL := joinURL(request.URL, path)
+
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+
+	if err != nil {
+		var result T
+		return result, err
+	}
+
+	httpRequest.Header.Set("X-Auth-Token", request.APIToken)
+	setRequestHeaders(httpRequest, request.Headers)
+
+	var client RequestDoer = defaultClient
+
+	if request.CACertPath != "" {
+		client, err = getClientWithCACert(request.CACertPath)
+
+		if err != nil {
+			var result T
+			return result, err
+		}
+	} else if request.AllowInsecure {
+		client = defaultInsecureClient
+	}
+
+	return decodeJsonFromRequest[T](client, httpRequest)
+}
+
+func getMinifluxIcon(ctx context.Context, request *MinifluxRequest, iconID int64) (template.URL, error) {
+	key := request.URL + "#" + strconv.FormatInt(iconID, 10)
+
+	minifluxIconsMutex.Lock()
+	cached, exists := minifluxIcons[key]
+	minifluxIconsMutex.Unlock()
+
+	if exists && time.Since(cached.fetchedAt) < minifluxIconsCacheDuration {
+		return cached.url, nil
+	}
+
+	response, err := queryMinifluxApi[minifluxIconResponseJson](ctx, request, "/v1/icons/"+strconv.FormatInt(iconID, 10), nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	var iconURL template.URL
+
+	if strings.HasPrefix(response.Data, "image/") {
+		iconURL = template.URL("data:" + response.Data)
+	}
+
+	minifluxIconsMutex.Lock()
+	minifluxIcons[key] = &cachedMinifluxIcon{fetchedAt: time.Now(), url: iconURL}
+	minifluxIconsMutex.Unlock()
+
+	return iconURL, nil
+}
+
+// returns the newest entries across all feeds, the icon of each feed is requested
+// separately, so the ones that fail to load are logged and left out
+func GetItemsFromMiniflux(ctx context.Context, logger *slog.Logger, request *MinifluxRequest, limit int) (RSSFeedItems, error) {
+	query := url.Values{
+		"order":     {"published_at"},
+		"direction": {"desc"},
+		"limit":     {strconv.Itoa(limit)},
+	}
+
+	if request.Status != "" {
+		query.Set("status", request.Status)
+	}
+
+	if request.MaxAge > 0 {
+		query.Set("published_after", strconv.FormatInt(time.Now().Add(-request.MaxAge).Unix(), 10))
+	}
+
+	response, err := queryMinifluxApi[minifluxEntriesResponseJson](ctx, request, "/v1/entries", query)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, err)
+	}
+
+	iconIDs := make([]int64, 0, len(response.Entries))
+	seenIconIDs := make(map[int64]bool, len(response.Entries))
+
+	for i := range response.Entries {
+		icon := response.Entries[i].Feed.Icon
+
+		if icon != nil && icon.IconID > 0 && !seenIconIDs[icon.IconID] {
+			seenIconIDs[icon.IconID] = true
+			iconIDs = append(iconIDs, icon.IconID)
+		}
+	}
+
+	job := newJob(func(iconID int64) (template.URL, error) {
+		return getMinifluxIcon(ctx, request, iconID)
+	}, iconIDs).withWorkers(4)
+
+	iconURLs, errs, err := workerPoolDo(job)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, err)
+	}
+
+	icons := make(map[int64]template.URL, len(iconIDs))
+
+	for i := range iconIDs {
+		if errs[i] != nil {
+			logger.Warn("Failed to fetch Miniflux icon", "error", errs[i], "host", urlHost(request.URL), "icon", iconIDs[i])
+			continue
+		}
+
+		icons[iconIDs[i]] = iconURLs[i]
+	}
+
+	items := make(RSSFeedItems, 0, len(response.Entries))
+
+	for i := range response.Entries {
+		entry := &response.Entries[i]
+
+		item := RSSFeedItem{
+			ChannelName: entry.Feed.Title,
+			ChannelURL:  entry.Feed.SiteURL,
+			Title:       entry.Title,
+			Link:        entry.URL,
+			PublishedAt: entry.PublishedAt,
+		}
+
+		if entry.Feed.Icon != nil {
+			item.ChannelIconURL = icons[entry.Feed.Icon.IconID]
+		}
+
+		if item.Title == "" {
+			item.Title = shortenFeedDescriptionLen(entry.Content, 100)
+		} else if request.IsDetailed {
+			item.Description = shortenFeedDescriptionLen(entry.Content, 200)
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
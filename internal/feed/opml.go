@@ -0,0 +1,58 @@
+package feed
+
+import (
+	"encoding/xml"
+	"os"
+)
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+type opmlDocument struct {
+	Outlines []opmlOutline `xml:"body>outline"`
+}
+
+// outlines can be nested arbitrarily deep when subscriptions are grouped into
+// categories, anything with an xmlUrl is considered a feed
+func collectFeedsFromOPMLOutlines(outlines []opmlOutline, requests []RSSFeedRequest) []RSSFeedRequest {
+	for i := range outlines {
+		outline := &outlines[i]
+
+		if outline.XMLURL != "" {
+			title := outline.Title
+
+			if title == "" {
+				title = outline.Text
+			}
+
+			requests = append(requests, RSSFeedRequest{
+				Url:   outline.XMLURL,
+				Title: title,
+			})
+		}
+
+		requests = collectFeedsFromOPMLOutlines(outline.Outlines, requests)
+	}
+
+	return requests
+}
+
+func ParseFeedRequestsFromOPMLFile(path string) ([]RSSFeedRequest, error) {
+	contents, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var document opmlDocument
+
+	if err = xml.Unmarshal(contents, &document); err != nil {
+		return nil, err
+	}
+
+	return collectFeedsFromOPMLOutlines(document.Outlines, nil), nil
+}
@@ -6,7 +6,6 @@ import (
 	"log/slog"
 	"net/http"
 	"sort"
-	"strings"
 )
 
 type piholeStatsResponse struct {
@@ -39,13 +38,13 @@ func (p *piholeTopBlockedDomains) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func FetchPiholeStats(instanceURL, token string) (*DNSStats, error) {
+func FetchPiholeStats(logger *slog.Logger, instanceURL, token string) (*DNSStats, error) {
 	if token == "" {
 		return nil, errors.New("missing API token")
 	}
 
-	requestURL := strings.TrimRight(instanceURL, "/") +
-		"/admin/api.php?summaryRaw&topItems&overTimeData10mins&auth=" + token
+	requestURL := joinURL(instanceURL, "admin/api.php") +
+		"?summaryRaw&topItems&overTimeData10mins&auth=" + token
 
 	request, err := http.NewRequest("GET", requestURL, nil)
 
@@ -85,8 +84,9 @@ func FetchPiholeStats(instanceURL, token string) (*DNSStats, error) {
 
 	// Pihole _should_ return data for the last 24 hours in a 10 minute interval, 6*24 = 144
 	if len(responseJson.QueriesSeries) != 144 || len(responseJson.BlockedSeries) != 144 {
-		slog.Warn(
+		logger.Warn(
 			"DNS stats for pihole: did not get expected 144 data points",
+			"host", urlHost(instanceURL),
 			"len(queries)", len(responseJson.QueriesSeries),
 			"len(blocked)", len(responseJson.BlockedSeries),
 		)
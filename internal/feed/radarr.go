@@ -0,0 +1,241 @@
+package feed
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type radarrCalendarResponseJson []struct {
+	Title           string        `json:"title"`
+	TitleSlug       string        `json:"titleSlug"`
+	ImdbID          string        `json:"imdbId"`
+	TmdbID          int           `json:"tmdbId"`
+	Overview        string        `json:"overview"`
+	Runtime         int           `json:"runtime"`
+	Certification   string        `json:"certification"`
+	InCinemas       string        `json:"inCinemas"`
+	DigitalRelease  string        `json:"digitalRelease"`
+	PhysicalRelease string        `json:"physicalRelease"`
+	Status          string        `json:"status"`
+	IsAvailable     bool          `json:"isAvailable"`
+	HasFile         bool          `json:"hasFile"`
+	MovieFile       *arrMediaFile `json:"movieFile"`
+	Images          []arrImage    `json:"images"`
+}
+
+// isAvailable takes into account the minimum availability set for the movie,
+// so a movie can be released while not yet being considered available
+func radarrStatusLabel(status string, isAvailable bool) string {
+	if isAvailable {
+		return "Available"
+	}
+
+	switch status {
+	case "tba":
+		return "TBA"
+	case "announced":
+		return "Announced"
+	case "inCinemas":
+		return "In Cinemas"
+	case "released":
+		return "Released"
+	}
+
+	return ""
+}
+
+func fetchReleasesFromRadarr(ctx context.Context, request *ArrReleaseRequest, start, end time.Time) (ArrReleases, error) {
+	response, err := queryArrCalendarApi[radarrCalendarResponseJson](ctx, request, "/api/v3/calendar", request.withExtraQuery(arrCalendarQuery(start, end)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make(ArrReleases, 0, len(response))
+
+	for i := range response {
+		movie := &response[i]
+
+		if request.Availability != "" && request.Availability != "any" && movie.Status != request.Availability {
+			continue
+		}
+
+		dates := []struct {
+			releaseType string
+			date        string
+		}{
+			{"In Cinemas", movie.InCinemas},
+			{"Digital", movie.DigitalRelease},
+			{"Physical", movie.PhysicalRelease},
+		}
+
+		for _, d := range dates {
+			releasedAt, ok := parseArrReleaseDate(d.date, start.Location(), request.DayStartHour)
+
+			if !ok || !isDayWithinWindow(releasedAt, start, end) {
+				continue
+			}
+
+			release := ArrRelease{
+				Source:        ArrSourceRadarr,
+				Title:         movie.Title,
+				Overview:      request.shortenOverview(movie.Overview),
+				URL:           request.linkTo("/movie/" + movie.TitleSlug),
+				ImageURL:      request.posterURL(request.findImageURL(movie.Images)),
+				ReleaseType:   d.releaseType,
+				Runtime:       movie.Runtime,
+				Certification: movie.Certification,
+				Status:        radarrStatusLabel(movie.Status, movie.IsAvailable),
+				ReleasedAt:    releasedAt,
+				Grabbed:       movie.HasFile,
+			}
+
+			if movie.HasFile && movie.MovieFile != nil {
+				release.Quality = movie.MovieFile.Quality.Quality.Name
+				release.FileSize = movie.MovieFile.Size
+			}
+
+			if movie.ImdbID != "" {
+				release.IMDbURL = "https://www.imdb.com/title/" + movie.ImdbID + "/"
+			}
+
+			if movie.TmdbID > 0 {
+				release.TMDbURL = "https://www.themoviedb.org/movie/" + strconv.Itoa(movie.TmdbID)
+			}
+
+			releases = append(releases, release)
+
+			break
+		}
+	}
+
+	return releases, nil
+}
+
+type radarrCollectionsResponseJson []struct {
+	Title     string `json:"title"`
+	Monitored bool   `json:"monitored"`
+	Movies    []struct {
+		TmdbID     int        `json:"tmdbId"`
+		Title      string     `json:"title"`
+		Year       int        `json:"year"`
+		Images     []arrImage `json:"images"`
+		IsExcluded bool       `json:"isExcluded"`
+	} `json:"movies"`
+}
+
+type radarrMoviesResponseJson []struct {
+	TmdbID      int    `json:"tmdbId"`
+	TitleSlug   string `json:"titleSlug"`
+	Status      string `json:"status"`
+	IsAvailable bool   `json:"isAvailable"`
+	HasFile     bool   `json:"hasFile"`
+}
+
+type RadarrCollectionMovie struct {
+	Title      string
+	Collection string
+	Year       int
+	URL        string
+	ImageURL   string
+	// the status of the movie in Radarr, Not in Library when it hasn't been added
+	Status    string
+	InLibrary bool
+}
+
+// collections only have the year that their movies come out in, so movies that aren't
+// in the library yet count as upcoming for the whole year, or when it isn't known
+func FetchRadarrCollectionMovies(ctx context.Context, request *ArrReleaseRequest, limit int, now time.Time) ([]RadarrCollectionMovie, error) {
+	collections, err := queryArrApi[radarrCollectionsResponseJson](ctx, request, "/api/v3/collection", nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	movies, err := queryArrApi[radarrMoviesResponseJson](ctx, request, "/api/v3/movie", nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	libraryIndexes := make(map[int]int, len(movies))
+
+	for i := range movies {
+		libraryIndexes[movies[i].TmdbID] = i
+	}
+
+	upcoming := make([]RadarrCollectionMovie, 0)
+	seen := make(map[int]bool)
+
+	for i := range collections {
+		collection := &collections[i]
+
+		if !collection.Monitored {
+			continue
+		}
+
+		for j := range collection.Movies {
+			movie := &collection.Movies[j]
+
+			if movie.IsExcluded || seen[movie.TmdbID] {
+				continue
+			}
+
+			result := RadarrCollectionMovie{
+				Title:      movie.Title,
+				Collection: collection.Title,
+				Year:       movie.Year,
+				ImageURL:   request.posterURL(request.findImageURL(movie.Images)),
+			}
+
+			if index, exists := libraryIndexes[movie.TmdbID]; exists {
+				libraryMovie := &movies[index]
+
+				if libraryMovie.HasFile || libraryMovie.IsAvailable || libraryMovie.Status == "released" {
+					continue
+				}
+
+				result.InLibrary = true
+				result.Status = radarrStatusLabel(libraryMovie.Status, libraryMovie.IsAvailable)
+				result.URL = request.linkTo("/movie/" + libraryMovie.TitleSlug)
+			} else {
+				if movie.Year != 0 && movie.Year < now.Year() {
+					continue
+				}
+
+				result.Status = "Not in Library"
+				result.URL = request.linkTo("/add/new?term=" + url.QueryEscape("tmdb:"+strconv.Itoa(movie.TmdbID)))
+			}
+
+			seen[movie.TmdbID] = true
+			upcoming = append(upcoming, result)
+		}
+	}
+
+	// movies without a year are the furthest away
+	slices.SortStableFunc(upcoming, func(a, b RadarrCollectionMovie) int {
+		aYear, bYear := a.Year, b.Year
+
+		if aYear == 0 {
+			aYear = math.MaxInt
+		}
+
+		if bYear == 0 {
+			bYear = math.MaxInt
+		}
+
+		return cmp.Or(cmp.Compare(aYear, bYear), strings.Compare(a.Title, b.Title))
+	})
+
+	if len(upcoming) > limit {
+		upcoming = upcoming[:limit]
+	}
+
+	return upcoming, nil
+}
@@ -21,7 +21,7 @@ type ReleaseRequest struct {
 	Token      *string
 }
 
-func FetchLatestReleases(requests []*ReleaseRequest) (AppReleases, error) {
+func FetchLatestReleases(logger *slog.Logger, requests []*ReleaseRequest) (AppReleases, error) {
 	job := newJob(fetchLatestReleaseTask, requests).withWorkers(20)
 	results, errs, err := workerPoolDo(job)
 
@@ -36,7 +36,7 @@ func FetchLatestReleases(requests []*ReleaseRequest) (AppReleases, error) {
 	for i := range results {
 		if errs[i] != nil {
 			failed++
-			slog.Error("Failed to fetch release", "source", requests[i].Source, "repository", requests[i].Repository, "error", errs[i])
+			logger.Error("Failed to fetch release", "source", requests[i].Source, "repository", requests[i].Repository, "error", errs[i])
 			continue
 		}
 
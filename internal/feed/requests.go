@@ -1,24 +1,38 @@
 package feed
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
 
 const defaultClientTimeout = 5 * time.Second
 
+// sent to self-hosted services that glance talks to on the user's behalf,
+// the application sets it on startup so that it includes its version
+var GlanceUserAgent = "glance"
+
 var defaultClient = &http.Client{
 	Timeout: defaultClientTimeout,
 }
 
+// custom transports need to set the proxy explicitly, only the default
+// transport uses the proxy environment variables on its own
 var insecureClientTransport = &http.Transport{
+	Proxy:           http.ProxyFromEnvironment,
 	TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 }
 
@@ -27,14 +41,113 @@ var defaultInsecureClient = &http.Client{
 	Transport: insecureClientTransport,
 }
 
+var clientsWithCACert = make(map[string]*http.Client)
+var clientsWithCACertMutex sync.Mutex
+
+// returns a client that trusts the certificates in the given PEM file on top of the
+// system ones, clients are reused between calls since the file is unlikely to change
+func getClientWithCACert(caCertPath string) (*http.Client, error) {
+	clientsWithCACertMutex.Lock()
+	defer clientsWithCACertMutex.Unlock()
+
+	if client, exists := clientsWithCACert[caCertPath]; exists {
+		return client, nil
+	}
+
+	caCert, err := os.ReadFile(caCertPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("reading CA certificate: %v", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("no valid certificates found in %s", caCertPath)
+	}
+
+	client := &http.Client{
+		Timeout: defaultClientTimeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}
+
+	clientsWithCACert[caCertPath] = client
+
+	return client, nil
+}
+
 type RequestDoer interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+// set by the application when metrics are enabled, called after each request made
+// through the decode helpers with the host it was made to, how long it took and
+// whether it failed, which includes responses with an error status code
+var RequestObserver func(host string, duration time.Duration, failed bool)
+
+type observedRequestDoer struct {
+	client RequestDoer
+}
+
+func (doer observedRequestDoer) Do(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := doer.client.Do(request)
+	RequestObserver(request.URL.Host, time.Since(start), err != nil || response.StatusCode >= 400)
+
+	return response, err
+}
+
+func observeRequests(client RequestDoer) RequestDoer {
+	if RequestObserver == nil {
+		return client
+	}
+
+	return observedRequestDoer{client: client}
+}
+
+// log lines only include the host so that the instance a failure came from can
+// be told apart without leaking credentials that some services take in the URL
+func urlHost(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+
+	return parsed.Host
+}
+
+// the Host header can't be set through the request's headers, it's set separately
+func setRequestHeaders(request *http.Request, headers map[string]string) {
+	for name, value := range headers {
+		if strings.EqualFold(name, "Host") {
+			request.Host = value
+		} else {
+			request.Header.Set(name, value)
+		}
+	}
+}
+
 func addBrowserUserAgentHeader(request *http.Request) {
 	request.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:123.0) Gecko/20100101 Firefox/123.0")
 }
 
+// for APIs that take secrets as query parameters, since request errors include the URL
+func redactSecretFromError(err error, secret string) error {
+	if err == nil || secret == "" {
+		return err
+	}
+
+	return errors.New(strings.ReplaceAll(err.Error(), secret, "REDACTED"))
+}
+
 func truncateString(s string, maxLen int) string {
 	asRunes := []rune(s)
 
@@ -46,39 +159,75 @@ func truncateString(s string, maxLen int) string {
 }
 
 func decodeJsonFromRequest[T any](client RequestDoer, request *http.Request) (T, error) {
-	response, err := client.Do(request)
 	var result T
+	body, err := readResponseBody(client, request)
 
 	if err != nil {
 		return result, err
 	}
 
-	defer response.Body.Close()
+	err = json.Unmarshal(body, &result)
 
-	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return result, fmt.Errorf("decoding response from %s: %v, response: %s", request.URL, err, truncateString(string(body), 256))
+	}
+
+	return result, nil
+}
+
+// same as decodeJsonFromRequest, except that an empty body or a 204, which some services
+// and the proxies in front of them respond with when there's nothing to return, results
+// in the zero value of T rather than an error
+func decodeJsonOrEmptyFromRequest[T any](client RequestDoer, request *http.Request) (T, error) {
+	var result T
+	body, err := readResponseBody(client, request, http.StatusNoContent)
 
 	if err != nil {
 		return result, err
 	}
 
-	if response.StatusCode != http.StatusOK {
-		return result, fmt.Errorf(
-			"unexpected status code %d for %s, response: %s",
-			response.StatusCode,
-			request.URL,
-			truncateString(string(body), 256),
-		)
+	if len(bytes.TrimSpace(body)) == 0 {
+		return result, nil
 	}
 
 	err = json.Unmarshal(body, &result)
 
 	if err != nil {
-		return result, err
+		return result, fmt.Errorf("decoding response from %s: %v, response: %s", request.URL, err, truncateString(string(body), 256))
 	}
 
 	return result, nil
 }
 
+// returns an error for any status code other than 200 and the additionally accepted ones
+func readResponseBody(client RequestDoer, request *http.Request, acceptedStatusCodes ...int) ([]byte, error) {
+	response, err := observeRequests(client).Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK && !slices.Contains(acceptedStatusCodes, response.StatusCode) {
+		return nil, fmt.Errorf(
+			"unexpected status code %d (%s) for %s, response: %s",
+			response.StatusCode,
+			http.StatusText(response.StatusCode),
+			request.URL,
+			truncateString(string(body), 256),
+		)
+	}
+
+	return body, nil
+}
+
 func decodeJsonFromRequestTask[T any](client RequestDoer) func(*http.Request) (T, error) {
 	return func(request *http.Request) (T, error) {
 		return decodeJsonFromRequest[T](client, request)
@@ -87,7 +236,7 @@ func decodeJsonFromRequestTask[T any](client RequestDoer) func(*http.Request) (T
 
 // TODO: tidy up, these are a copy of the above but with a line changed
 func decodeXmlFromRequest[T any](client RequestDoer, request *http.Request) (T, error) {
-	response, err := client.Do(request)
+	response, err := observeRequests(client).Do(request)
 	var result T
 
 	if err != nil {
@@ -104,8 +253,9 @@ func decodeXmlFromRequest[T any](client RequestDoer, request *http.Request) (T,
 
 	if response.StatusCode != http.StatusOK {
 		return result, fmt.Errorf(
-			"unexpected status code %d for %s, response: %s",
+			"unexpected status code %d (%s) for %s, response: %s",
 			response.StatusCode,
+			http.StatusText(response.StatusCode),
 			request.URL,
 			truncateString(string(body), 256),
 		)
@@ -114,7 +264,7 @@ func decodeXmlFromRequest[T any](client RequestDoer, request *http.Request) (T,
 	err = xml.Unmarshal(body, &result)
 
 	if err != nil {
-		return result, err
+		return result, fmt.Errorf("decoding response from %s: %v, response: %s", request.URL, err, truncateString(string(body), 256))
 	}
 
 	return result, nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"html"
+	"html/template"
 	"log/slog"
 	"net/url"
 	"regexp"
@@ -16,14 +17,20 @@ import (
 )
 
 type RSSFeedItem struct {
-	ChannelName string
-	ChannelURL  string
-	Title       string
-	Link        string
-	ImageURL    string
-	Categories  []string
-	Description string
-	PublishedAt time.Time
+	ChannelName    string
+	ChannelURL     string
+	ChannelIconURL template.URL
+	Title          string
+	Link           string
+	ImageURL       string
+	Categories     []string
+	Description    string
+	PublishedAt    time.Time
+	// the name of the instance that the item came from when a widget shows
+	// items from several of them, see GetItemsFromFreshRssInstances
+	SourceName string
+	// whether the item was published recently enough to be highlighted, see FreshRssRequest
+	IsNew bool
 }
 
 // doesn't cover all cases but works the vast majority of the time
@@ -162,7 +169,7 @@ func getItemsFromRSSFeedTask(request RSSFeedRequest) ([]RSSFeedItem, error) {
 			rssItem.ImageURL = url
 		} else if feed.Image != nil {
 			if len(feed.Image.URL) > 0 && feed.Image.URL[0] == '/' {
-				rssItem.ImageURL = strings.TrimRight(feed.Link, "/") + feed.Image.URL
+				rssItem.ImageURL = joinURL(feed.Link, feed.Image.URL)
 			} else {
 				rssItem.ImageURL = feed.Image.URL
 			}
@@ -210,7 +217,7 @@ func findThumbnailInItemExtensions(item *gofeed.Item) string {
 	return recursiveFindThumbnailInExtensions(media)
 }
 
-func GetItemsFromRSSFeeds(requests []RSSFeedRequest) (RSSFeedItems, error) {
+func GetItemsFromRSSFeeds(logger *slog.Logger, requests []RSSFeedRequest) (RSSFeedItems, error) {
 	job := newJob(getItemsFromRSSFeedTask, requests).withWorkers(10)
 	feeds, errs, err := workerPoolDo(job)
 
@@ -225,7 +232,7 @@ func GetItemsFromRSSFeeds(requests []RSSFeedRequest) (RSSFeedItems, error) {
 	for i := range feeds {
 		if errs[i] != nil {
 			failed++
-			slog.Error("failed to get rss feed", "error", errs[i], "url", requests[i].Url)
+			logger.Error("failed to get rss feed", "error", errs[i], "host", urlHost(requests[i].Url), "url", requests[i].Url)
 			continue
 		}
 
@@ -0,0 +1,536 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+type sonarrCalendarResponseJson []struct {
+	SeasonNumber  int           `json:"seasonNumber"`
+	EpisodeNumber int           `json:"episodeNumber"`
+	Title         string        `json:"title"`
+	Overview      string        `json:"overview"`
+	AirDate       string        `json:"airDate"`
+	AirDateUtc    string        `json:"airDateUtc"`
+	FinaleType    string        `json:"finaleType"`
+	HasFile       bool          `json:"hasFile"`
+	EpisodeFile   *arrMediaFile `json:"episodeFile"`
+	Images        []arrImage    `json:"images"`
+	TVDbID        int           `json:"tvdbId"`
+	Series        struct {
+		TVDbID     int        `json:"tvdbId"`
+		Title      string     `json:"title"`
+		TitleSlug  string     `json:"titleSlug"`
+		Network    string     `json:"network"`
+		SeriesType string     `json:"seriesType"`
+		AirTime    string     `json:"airTime"`
+		Images     []arrImage `json:"images"`
+	} `json:"series"`
+}
+
+func fetchReleasesFromSonarr(ctx context.Context, request *ArrReleaseRequest, start, end time.Time) (ArrReleases, error) {
+	query := arrCalendarQuery(start, end)
+	query.Set("includeSeries", "true")
+	query.Set("includeEpisodeFile", "true")
+
+	if request.ImageSource == "episode" {
+		query.Set("includeEpisodeImages", "true")
+	}
+
+	response, err := queryArrCalendarApi[sonarrCalendarResponseJson](ctx, request, "/api/v3/calendar", request.withExtraQuery(query))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sonarrReleasesFromResponse(request, response, start, end)
+}
+
+// episode images are only returned with includeEpisodeImages, and episodes
+// that haven't aired yet usually don't have a still
+func (request *ArrReleaseRequest) findEpisodeImageURL(episodeImages, seriesImages []arrImage) string {
+	if request.ImageSource == "episode" {
+		if imageURL := findArrImageURL(episodeImages, "screenshot"); imageURL != "" {
+			return imageURL
+		}
+	}
+
+	return request.findImageURL(seriesImages)
+}
+
+func sonarrReleasesFromResponse(request *ArrReleaseRequest, response sonarrCalendarResponseJson, start, end time.Time) (ArrReleases, error) {
+	releases := make(ArrReleases, 0, len(response))
+
+	for i := range response {
+		episode := &response[i]
+
+		// episodes that haven't been given an air date yet can't fall within the window
+		if episode.AirDateUtc == "" {
+			continue
+		}
+
+		airDate, err := time.Parse(time.RFC3339, episode.AirDateUtc)
+
+		// a single bad episode shouldn't take the rest of the calendar down with it
+		if err != nil {
+			slog.Warn("Skipping Sonarr episode with an invalid air date", "error", err, "series", episode.Series.Title, "host", urlHost(request.URL))
+			continue
+		}
+
+		airDate = airDate.In(start.Location())
+
+		if request.AirDateSource == "network" {
+			airDate = sonarrNetworkAirDate(episode.AirDate, episode.Series.AirTime, airDate)
+		}
+
+		if !isWithinWindow(airDate, start, end) {
+			continue
+		}
+
+		if len(request.Networks) > 0 && !slices.ContainsFunc(request.Networks, func(network string) bool {
+			return strings.EqualFold(network, episode.Series.Network)
+		}) {
+			continue
+		}
+
+		episodeCode := request.episodeCode(episode.SeasonNumber, episode.EpisodeNumber)
+		subtitle := episodeCode
+
+		if episode.Title != "" {
+			subtitle += " · " + episode.Title
+		}
+
+		release := ArrRelease{
+			Source:        ArrSourceSonarr,
+			Title:         episode.Series.Title,
+			Subtitle:      subtitle,
+			Overview:      request.shortenOverview(episode.Overview),
+			URL:           request.linkTo(fmt.Sprintf("/series/%s#season%d", episode.Series.TitleSlug, episode.SeasonNumber)),
+			ImageURL:      request.posterURL(request.findEpisodeImageURL(episode.Images, episode.Series.Images)),
+			SeasonNumber:  episode.SeasonNumber,
+			EpisodeNumber: episode.EpisodeNumber,
+			EpisodeCode:   episodeCode,
+			Network:       episode.Series.Network,
+			SeriesType:    episode.Series.SeriesType,
+			ReleasedAt:    airDate,
+			Grabbed:       episode.HasFile,
+			TVDbID:        episode.TVDbID,
+			SeriesTVDbID:  episode.Series.TVDbID,
+		}
+
+		if finale, ok := sonarrFinaleTypes[episode.FinaleType]; ok {
+			release.Finale = finale
+		}
+
+		if episode.HasFile && episode.EpisodeFile != nil {
+			release.Quality = episode.EpisodeFile.Quality.Quality.Name
+			release.FileSize = episode.EpisodeFile.Size
+		}
+
+		releases = append(releases, release)
+	}
+
+	if request.CollapseSeasons {
+		releases = collapseSeasonPacks(releases)
+	}
+
+	return releases, nil
+}
+
+// streaming services often release whole seasons at once, below this many
+// episodes of a season airing on the same day they're still listed one by one
+const minSeasonPackEpisodes = 3
+
+// replaces the episodes of a season that all aired on the same day with a single
+// release, placed where the first of them was
+func collapseSeasonPacks(releases ArrReleases) ArrReleases {
+	type seasonDay struct {
+		url  string
+		date string
+	}
+
+	counts := make(map[seasonDay]int)
+
+	for i := range releases {
+		counts[seasonDay{releases[i].URL, releases[i].ReleasedAt.Format("2006-01-02")}]++
+	}
+
+	collapsed := make(ArrReleases, 0, len(releases))
+	packs := make(map[seasonDay]int)
+
+	for i := range releases {
+		episode := &releases[i]
+		key := seasonDay{episode.URL, episode.ReleasedAt.Format("2006-01-02")}
+
+		if counts[key] < minSeasonPackEpisodes {
+			collapsed = append(collapsed, *episode)
+			continue
+		}
+
+		index, exists := packs[key]
+
+		if !exists {
+			pack := *episode
+			pack.Subtitle = fmt.Sprintf("Season %d · %d episodes", episode.SeasonNumber, counts[key])
+			pack.Overview = ""
+			pack.EpisodeNumber = 0
+			pack.EpisodeCount = counts[key]
+			packs[key] = len(collapsed)
+			collapsed = append(collapsed, pack)
+			continue
+		}
+
+		pack := &collapsed[index]
+
+		if episode.ReleasedAt.Before(pack.ReleasedAt) {
+			pack.ReleasedAt = episode.ReleasedAt
+		}
+
+		// only shown as grabbed once every episode has been
+		pack.Grabbed = pack.Grabbed && episode.Grabbed
+		pack.FileSize += episode.FileSize
+
+		if pack.Quality != episode.Quality {
+			pack.Quality = ""
+		}
+
+		// usually the last episode of the pack
+		if episode.Finale != "" {
+			pack.Finale = episode.Finale
+		}
+	}
+
+	return collapsed
+}
+
+// only reported by Sonarr v4 and later
+var sonarrFinaleTypes = map[string]string{
+	"series":    "Series Finale",
+	"season":    "Season Finale",
+	"midseason": "Midseason Finale",
+}
+
+// the date and time the episode airs on in the network's own timezone, placed on the
+// same wall clock in the local timezone so that e.g. a show airing late in the evening
+// in the US isn't shown on the next day elsewhere. falls back to the time of the UTC
+// based air date when Sonarr doesn't know the air time of the series
+func sonarrNetworkAirDate(airDate, airTime string, utcAirDate time.Time) time.Time {
+	date, err := time.Parse("2006-01-02", airDate)
+
+	if err != nil {
+		return utcAirDate
+	}
+
+	hour, minute := utcAirDate.Hour(), utcAirDate.Minute()
+
+	if clock, err := time.Parse("15:04", airTime); err == nil {
+		hour, minute = clock.Hour(), clock.Minute()
+	}
+
+	return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, utcAirDate.Location())
+}
+
+// premieres are the first episodes of a season, specials are excluded since their
+// numbering doesn't follow any order
+func FetchSonarrPremieres(ctx context.Context, request *ArrReleaseRequest, now time.Time, days int) (ArrReleases, error) {
+	start := getStartOfDay(now, now.Location())
+	end := getEndOfDay(now.AddDate(0, 0, days-1), now.Location())
+
+	episodes, err := fetchReleasesFromSonarr(ctx, request, start, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	premieres := make(ArrReleases, 0, len(episodes))
+
+	for i := range episodes {
+		episode := &episodes[i]
+
+		if episode.EpisodeNumber != 1 || episode.SeasonNumber < 1 {
+			continue
+		}
+
+		if episode.SeasonNumber == 1 {
+			episode.ReleaseType = "Series Premiere"
+		} else {
+			episode.ReleaseType = "Season Premiere"
+		}
+
+		premieres = append(premieres, *episode)
+	}
+
+	return request.filterWeekdays(premieres).SortByReleaseTime(), nil
+}
+
+type SonarrNextAiring struct {
+	Release   ArrRelease
+	DaysUntil int
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(math.Round(getStartOfDay(to, to.Location()).Sub(getStartOfDay(from, from.Location())).Hours() / 24))
+}
+
+func fetchSonarrNextAiringTask(ctx context.Context, now time.Time, days int) func(*ArrReleaseRequest) (ArrReleases, error) {
+	return func(request *ArrReleaseRequest) (ArrReleases, error) {
+		// starts where the request's usual window ends so that the two don't overlap
+		_, start := request.window(now)
+		end := atHourOfDay(start.AddDate(0, 0, days), request.DayStartHour)
+		episodes, err := fetchReleasesFromSonarr(ctx, request, start, end)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return request.filterWeekdays(episodes), nil
+	}
+}
+
+// the next episode of each series airing within the given number of days after the
+// requests' usual window, limited to the given series when there are any
+func FetchSonarrNextAiring(ctx context.Context, logger *slog.Logger, requests []*ArrReleaseRequest, now time.Time, days int, series []string) ([]SonarrNextAiring, error) {
+	job := newJob(fetchSonarrNextAiringTask(ctx, now, days), requests).withWorkers(10)
+	results, errs, err := workerPoolDo(job)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var failed int
+	var episodes ArrReleases
+
+	for i := range results {
+		if errs[i] != nil {
+			failed++
+			logger.Error("Failed to fetch next airing episodes", "host", urlHost(requests[i].URL), "error", errs[i])
+			continue
+		}
+
+		episodes = append(episodes, results[i]...)
+	}
+
+	if failed == len(requests) {
+		return nil, fmt.Errorf("%w: %v", ErrNoContent, errs[0])
+	}
+
+	episodes.SortByReleaseTime()
+
+	seen := make(map[string]struct{})
+	nextAiring := make([]SonarrNextAiring, 0, len(episodes))
+
+	for i := range episodes {
+		episode := &episodes[i]
+
+		if _, exists := seen[episode.Title]; exists {
+			continue
+		}
+
+		if len(series) > 0 && !slices.ContainsFunc(series, func(title string) bool {
+			return strings.EqualFold(title, episode.Title)
+		}) {
+			continue
+		}
+
+		seen[episode.Title] = struct{}{}
+		nextAiring = append(nextAiring, SonarrNextAiring{
+			Release:   *episode,
+			DaysUntil: daysBetween(now, episode.ReleasedAt),
+		})
+	}
+
+	if failed > 0 {
+		return nextAiring, fmt.Errorf("%w: could not get next airing episodes from %d instances", ErrPartialContent, failed)
+	}
+
+	return nextAiring, nil
+}
+
+type sonarrSeriesResponseJson []struct {
+	Monitored  bool `json:"monitored"`
+	Statistics struct {
+		EpisodeFileCount int   `json:"episodeFileCount"`
+		EpisodeCount     int   `json:"episodeCount"`
+		SizeOnDisk       int64 `json:"sizeOnDisk"`
+	} `json:"statistics"`
+}
+
+type SonarrStats struct {
+	Series         int
+	MonitoredCount int
+	EpisodesOnDisk int
+	// monitored episodes that have already aired, same as what Sonarr shows as the total
+	TotalEpisodes int
+	SizeOnDisk    int64
+}
+
+func FetchSonarrStats(ctx context.Context, request *ArrReleaseRequest) (*SonarrStats, error) {
+	response, err := queryArrApi[sonarrSeriesResponseJson](ctx, request, "/api/v3/series", nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &SonarrStats{Series: len(response)}
+
+	for i := range response {
+		series := &response[i]
+
+		if series.Monitored {
+			stats.MonitoredCount++
+		}
+
+		stats.EpisodesOnDisk += series.Statistics.EpisodeFileCount
+		stats.TotalEpisodes += series.Statistics.EpisodeCount
+		stats.SizeOnDisk += series.Statistics.SizeOnDisk
+	}
+
+	return stats, nil
+}
+
+type sonarrCutoffUnmetRecordJson struct {
+	SeasonNumber  int           `json:"seasonNumber"`
+	EpisodeNumber int           `json:"episodeNumber"`
+	Title         string        `json:"title"`
+	AirDateUtc    string        `json:"airDateUtc"`
+	EpisodeFile   *arrMediaFile `json:"episodeFile"`
+	Series        struct {
+		Title            string     `json:"title"`
+		TitleSlug        string     `json:"titleSlug"`
+		QualityProfileID int        `json:"qualityProfileId"`
+		Images           []arrImage `json:"images"`
+	} `json:"series"`
+}
+
+type sonarrQualityProfilesResponseJson []struct {
+	ID     int `json:"id"`
+	Cutoff int `json:"cutoff"`
+	Items  []struct {
+		// only set for groups of qualities, which the cutoff can also point to
+		ID      int    `json:"id"`
+		Name    string `json:"name"`
+		Quality *struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"quality"`
+	} `json:"items"`
+}
+
+type SonarrCutoffUnmetEpisode struct {
+	SeriesTitle string
+	Subtitle    string
+	URL         string
+	ImageURL    string
+	Quality     string
+	// the quality the episode's profile upgrades up to, empty if it couldn't be found
+	CutoffQuality string
+	AiredAt       time.Time
+	// when the current file was added, zero if Sonarr didn't report it
+	FileAddedAt time.Time
+}
+
+type SonarrCutoffUnmet struct {
+	Episodes []SonarrCutoffUnmetEpisode
+	// can be more than the number of episodes when they were limited
+	Total int
+}
+
+// maps the ids of quality profiles to the name of their cutoff quality
+func fetchSonarrCutoffNames(ctx context.Context, request *ArrReleaseRequest) (map[int]string, error) {
+	profiles, err := queryArrApi[sonarrQualityProfilesResponseJson](ctx, request, "/api/v3/qualityprofile", nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string, len(profiles))
+
+	for i := range profiles {
+		profile := &profiles[i]
+
+		for j := range profile.Items {
+			item := &profile.Items[j]
+
+			if item.Quality != nil && item.Quality.ID == profile.Cutoff {
+				names[profile.ID] = item.Quality.Name
+				break
+			}
+
+			if item.Quality == nil && item.ID == profile.Cutoff {
+				names[profile.ID] = item.Name
+				break
+			}
+		}
+	}
+
+	return names, nil
+}
+
+// the monitored episodes whose files are below the cutoff of their quality profile,
+// most recently aired first
+func FetchSonarrCutoffUnmet(ctx context.Context, request *ArrReleaseRequest, limit int) (*SonarrCutoffUnmet, error) {
+	query := url.Values{}
+	query.Set("sortKey", "airDateUtc")
+	query.Set("sortDirection", "descending")
+	query.Set("monitored", "true")
+	query.Set("includeSeries", "true")
+	query.Set("includeEpisodeFile", "true")
+
+	records, total, err := queryArrPagedApi[sonarrCutoffUnmetRecordJson](ctx, request, "/api/v3/wanted/cutoff", query, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the episodes are still worth showing without the cutoff qualities
+	cutoffNames, cutoffErr := fetchSonarrCutoffNames(ctx, request)
+
+	result := &SonarrCutoffUnmet{
+		Episodes: make([]SonarrCutoffUnmetEpisode, 0, len(records)),
+		Total:    total,
+	}
+
+	for i := range records {
+		record := &records[i]
+
+		subtitle := request.episodeCode(record.SeasonNumber, record.EpisodeNumber)
+
+		if record.Title != "" {
+			subtitle += " · " + record.Title
+		}
+
+		episode := SonarrCutoffUnmetEpisode{
+			SeriesTitle:   record.Series.Title,
+			Subtitle:      subtitle,
+			URL:           request.linkTo(fmt.Sprintf("/series/%s#season%d", record.Series.TitleSlug, record.SeasonNumber)),
+			ImageURL:      request.posterURL(request.findImageURL(record.Series.Images)),
+			CutoffQuality: cutoffNames[record.Series.QualityProfileID],
+		}
+
+		if airedAt, err := time.Parse(time.RFC3339, record.AirDateUtc); err == nil {
+			episode.AiredAt = airedAt
+		}
+
+		if record.EpisodeFile != nil {
+			episode.Quality = record.EpisodeFile.Quality.Quality.Name
+
+			if addedAt, err := time.Parse(time.RFC3339, record.EpisodeFile.DateAdded); err == nil {
+				episode.FileAddedAt = addedAt
+			}
+		}
+
+		result.Episodes = append(result.Episodes, episode)
+	}
+
+	if cutoffErr != nil {
+		return result, fmt.Errorf("%w: could not get the quality profiles: %v", ErrPartialContent, cutoffErr)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,209 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TautulliRequest struct {
+	URL           string
+	APIKey        string
+	AllowInsecure bool
+	HistoryLimit  int
+}
+
+type TautulliSession struct {
+	User     string
+	Title    string
+	Subtitle string
+	Player   string
+	State    string
+	Progress int
+}
+
+type TautulliHistoryItem struct {
+	User      string
+	Title     string
+	Subtitle  string
+	WatchedAt time.Time
+	Progress  int
+}
+
+type TautulliActivity struct {
+	Sessions []TautulliSession
+	History  []TautulliHistoryItem
+}
+
+type tautulliResponseJson[T any] struct {
+	Response struct {
+		Result  string `json:"result"`
+		Message string `json:"message"`
+		Data    T      `json:"data"`
+	} `json:"response"`
+}
+
+// Tautulli returns most numbers as strings, sometimes as empty ones
+type tautulliInt int
+
+func (i *tautulliInt) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+
+	if value == "" || value == "null" {
+		*i = 0
+		return nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+
+	if err != nil {
+		return err
+	}
+
+	*i = tautulliInt(parsed)
+
+	return nil
+}
+
+type tautulliMediaJson struct {
+	User             string      `json:"friendly_name"`
+	MediaType        string      `json:"media_type"`
+	Title            string      `json:"title"`
+	ParentTitle      string      `json:"parent_title"`
+	GrandparentTitle string      `json:"grandparent_title"`
+	ParentMediaIndex tautulliInt `json:"parent_media_index"`
+	MediaIndex       tautulliInt `json:"media_index"`
+	Year             tautulliInt `json:"year"`
+}
+
+type tautulliActivityJson struct {
+	Sessions []struct {
+		tautulliMediaJson
+		Player          string      `json:"player"`
+		State           string      `json:"state"`
+		ProgressPercent tautulliInt `json:"progress_percent"`
+	} `json:"sessions"`
+}
+
+type tautulliHistoryJson struct {
+	Data []struct {
+		tautulliMediaJson
+		Date            tautulliInt `json:"date"`
+		PercentComplete tautulliInt `json:"percent_complete"`
+	} `json:"data"`
+}
+
+func (media *tautulliMediaJson) titles() (string, string) {
+	switch media.MediaType {
+	case "episode":
+		return media.GrandparentTitle, fmt.Sprintf("S%02dE%02d · %s", media.ParentMediaIndex, media.MediaIndex, media.Title)
+	case "track":
+		return media.Title, media.GrandparentTitle + " · " + media.ParentTitle
+	}
+
+	if media.Year > 0 {
+		return media.Title, strconv.Itoa(int(media.Year))
+	}
+
+	return media.Title, ""
+}
+
+func queryTautulliApi[T any](ctx context.Context, request *TautulliRequest, cmd string, params url.Values) (T, error) {
+	var result T
+
+	query := url.Values{}
+	query.Set("apikey", request.APIKey)
+	query.Set("cmd", cmd)
+
+	for key, values := range params {
+		query[key] = values
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", joinURL(request.URL, "api/v2")+"?"+query.Encode(), nil)
+
+	if err != nil {
+		return result, err
+	}
+
+	var client RequestDoer = defaultClient
+
+	if request.AllowInsecure {
+		client = defaultInsecureClient
+	}
+
+	response, err := decodeJsonFromRequest[tautulliResponseJson[T]](client, httpRequest)
+
+	if err != nil {
+		return result, redactSecretFromError(err, request.APIKey)
+	}
+
+	if response.Response.Result != "success" {
+		if response.Response.Message != "" {
+			return result, errors.New(response.Response.Message)
+		}
+
+		return result, fmt.Errorf("unexpected result '%s' for %s", response.Response.Result, cmd)
+	}
+
+	return response.Response.Data, nil
+}
+
+func FetchTautulliActivity(ctx context.Context, request *TautulliRequest) (*TautulliActivity, error) {
+	activityResponse, err := queryTautulliApi[tautulliActivityJson](ctx, request, "get_activity", nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &TautulliActivity{
+		Sessions: make([]TautulliSession, 0, len(activityResponse.Sessions)),
+	}
+
+	for i := range activityResponse.Sessions {
+		session := &activityResponse.Sessions[i]
+		title, subtitle := session.titles()
+
+		activity.Sessions = append(activity.Sessions, TautulliSession{
+			User:     session.User,
+			Title:    title,
+			Subtitle: subtitle,
+			Player:   session.Player,
+			State:    session.State,
+			Progress: int(session.ProgressPercent),
+		})
+	}
+
+	if request.HistoryLimit <= 0 {
+		return activity, nil
+	}
+
+	historyResponse, err := queryTautulliApi[tautulliHistoryJson](ctx, request, "get_history", url.Values{
+		"length": {strconv.Itoa(request.HistoryLimit)},
+	})
+
+	if err != nil {
+		return activity, fmt.Errorf("%w: could not get history: %v", ErrPartialContent, err)
+	}
+
+	activity.History = make([]TautulliHistoryItem, 0, len(historyResponse.Data))
+
+	for i := range historyResponse.Data {
+		item := &historyResponse.Data[i]
+		title, subtitle := item.titles()
+
+		activity.History = append(activity.History, TautulliHistoryItem{
+			User:      item.User,
+			Title:     title,
+			Subtitle:  subtitle,
+			WatchedAt: time.Unix(int64(item.Date), 0),
+			Progress:  int(item.PercentComplete),
+		})
+	}
+
+	return activity, nil
+}
@@ -150,7 +150,13 @@ const twitchChannelStatusOperationRequestBody = `[{"operationName":"ChannelShell
 // what the limit is for max operations per request and batch operations in
 // multiple requests if number of channels exceeds allowed limit.
 
-func fetchChannelFromTwitchTask(channel string) (TwitchChannel, error) {
+func fetchChannelFromTwitchTask(logger *slog.Logger) func(string) (TwitchChannel, error) {
+	return func(channel string) (TwitchChannel, error) {
+		return fetchChannelFromTwitch(logger, channel)
+	}
+}
+
+func fetchChannelFromTwitch(logger *slog.Logger, channel string) (TwitchChannel, error) {
 	result := TwitchChannel{
 		Login: strings.ToLower(channel),
 	}
@@ -214,7 +220,7 @@ func fetchChannelFromTwitchTask(channel string) (TwitchChannel, error) {
 			if err == nil {
 				result.LiveSince = startedAt
 			} else {
-				slog.Warn("failed to parse twitch stream started at", "error", err, "started_at", streamMetadata.UserOrNull.Stream.StartedAt)
+				logger.Warn("failed to parse twitch stream started at", "channel", channel, "error", err, "started_at", streamMetadata.UserOrNull.Stream.StartedAt)
 			}
 		}
 	}
@@ -222,10 +228,10 @@ func fetchChannelFromTwitchTask(channel string) (TwitchChannel, error) {
 	return result, nil
 }
 
-func FetchChannelsFromTwitch(channelLogins []string) (TwitchChannels, error) {
+func FetchChannelsFromTwitch(logger *slog.Logger, channelLogins []string) (TwitchChannels, error) {
 	result := make(TwitchChannels, 0, len(channelLogins))
 
-	job := newJob(fetchChannelFromTwitchTask, channelLogins).withWorkers(10)
+	job := newJob(fetchChannelFromTwitchTask(logger), channelLogins).withWorkers(10)
 	channels, errs, err := workerPoolDo(job)
 
 	if err != nil {
@@ -237,7 +243,7 @@ func FetchChannelsFromTwitch(channelLogins []string) (TwitchChannels, error) {
 	for i := range channels {
 		if errs[i] != nil {
 			failed++
-			slog.Warn("failed to fetch twitch channel", "channel", channelLogins[i], "error", errs[i])
+			logger.Warn("failed to fetch twitch channel", "channel", channelLogins[i], "error", errs[i])
 			continue
 		}
 
@@ -8,6 +8,7 @@ import (
 	"slices"
 	"strings"
 	"time"
+	"unicode"
 )
 
 var (
@@ -86,6 +87,28 @@ func stripURLScheme(url string) string {
 	return urlSchemePattern.ReplaceAllString(url, "")
 }
 
+// joins the parts with a single slash between each of them, whether or not they
+// already start or end with one, so that configured base URLs can have a trailing
+// slash. only the slashes where the parts meet are touched, so the last part can
+// end with a query, fragment or a trailing slash
+func joinURL(base string, parts ...string) string {
+	joined := strings.TrimRight(base, "/")
+
+	for i, part := range parts {
+		part = strings.TrimLeft(part, "/")
+
+		if i < len(parts)-1 {
+			part = strings.TrimRight(part, "/")
+		}
+
+		if part != "" {
+			joined += "/" + part
+		}
+	}
+
+	return joined
+}
+
 func limitStringLength(s string, max int) (string, bool) {
 	asRunes := []rune(s)
 
@@ -96,6 +119,22 @@ func limitStringLength(s string, max int) (string, bool) {
 	return s, false
 }
 
+// cuts the string at the last whitespace before maxLen so that words are never
+// split in half and appends an ellipsis if anything was removed
+func limitStringLengthAtWordBoundary(s string, maxLen int) string {
+	s, limited := limitStringLength(s, maxLen)
+
+	if !limited {
+		return s
+	}
+
+	if i := strings.LastIndexFunc(s, unicode.IsSpace); i > 0 {
+		s = s[:i]
+	}
+
+	return strings.TrimRight(s, " ,.;:-") + "…"
+}
+
 func parseRFC3339Time(t string) time.Time {
 	parsed, err := time.Parse(time.RFC3339, t)
 
@@ -0,0 +1,27 @@
+package feed
+
+import "testing"
+
+func TestJoinURL(t *testing.T) {
+	tests := []struct {
+		base     string
+		parts    []string
+		expected string
+	}{
+		{"http://localhost:8989", []string{"/api/v3/calendar"}, "http://localhost:8989/api/v3/calendar"},
+		{"http://localhost:8989/", []string{"/api/v3/calendar"}, "http://localhost:8989/api/v3/calendar"},
+		{"http://localhost:8989//", []string{"api/v3/calendar"}, "http://localhost:8989/api/v3/calendar"},
+		{"http://localhost/sonarr/", []string{"/series/some-show#season2"}, "http://localhost/sonarr/series/some-show#season2"},
+		{"http://localhost:8096/", []string{"Items", "abc", "/Images/Primary"}, "http://localhost:8096/Items/abc/Images/Primary"},
+		{"http://localhost:8096", []string{"Items/", "/abc/"}, "http://localhost:8096/Items/abc/"},
+		{"http://localhost:8096", []string{"web/#/details?id=abc"}, "http://localhost:8096/web/#/details?id=abc"},
+		{"http://localhost/", []string{"", "/api.php"}, "http://localhost/api.php"},
+		{"http://localhost/", nil, "http://localhost"},
+	}
+
+	for _, test := range tests {
+		if joined := joinURL(test.base, test.parts...); joined != test.expected {
+			t.Errorf("joining %q with %q: expected %q, got %q", test.base, test.parts, test.expected, joined)
+		}
+	}
+}
@@ -27,7 +27,7 @@ type marketResponseJson struct {
 // TODO: allow changing chart time frame
 const marketChartDays = 21
 
-func FetchMarketsDataFromYahoo(marketRequests []MarketRequest) (Markets, error) {
+func FetchMarketsDataFromYahoo(logger *slog.Logger, marketRequests []MarketRequest) (Markets, error) {
 	requests := make([]*http.Request, 0, len(marketRequests))
 
 	for i := range marketRequests {
@@ -48,7 +48,7 @@ func FetchMarketsDataFromYahoo(marketRequests []MarketRequest) (Markets, error)
 	for i := range responses {
 		if errs[i] != nil {
 			failed++
-			slog.Error("Failed to fetch market data", "symbol", marketRequests[i].Symbol, "error", errs[i])
+			logger.Error("Failed to fetch market data", "host", requests[i].URL.Host, "symbol", marketRequests[i].Symbol, "error", errs[i])
 			continue
 		}
 
@@ -56,7 +56,7 @@ func FetchMarketsDataFromYahoo(marketRequests []MarketRequest) (Markets, error)
 
 		if len(response.Chart.Result) == 0 {
 			failed++
-			slog.Error("Market response contains no data", "symbol", marketRequests[i].Symbol)
+			logger.Error("Market response contains no data", "host", requests[i].URL.Host, "symbol", marketRequests[i].Symbol)
 			continue
 		}
 
@@ -37,7 +37,7 @@ func parseYoutubeFeedTime(t string) time.Time {
 	return parsedTime
 }
 
-func FetchYoutubeChannelUploads(channelIds []string, videoUrlTemplate string, includeShorts bool) (Videos, error) {
+func FetchYoutubeChannelUploads(logger *slog.Logger, channelIds []string, videoUrlTemplate string, includeShorts bool) (Videos, error) {
 	requests := make([]*http.Request, 0, len(channelIds))
 
 	for i := range channelIds {
@@ -68,7 +68,7 @@ func FetchYoutubeChannelUploads(channelIds []string, videoUrlTemplate string, in
 	for i := range responses {
 		if errs[i] != nil {
 			failed++
-			slog.Error("Failed to fetch youtube feed", "channel", channelIds[i], "error", errs[i])
+			logger.Error("Failed to fetch youtube feed", "host", requests[i].URL.Host, "channel", channelIds[i], "error", errs[i])
 			continue
 		}
 
@@ -8,19 +8,24 @@ import (
 type CliIntent uint8
 
 const (
-	CliIntentServe       CliIntent = iota
-	CliIntentCheckConfig           = iota
+	CliIntentServe           CliIntent = iota
+	CliIntentCheckConfig               = iota
+	CliIntentTestConnections           = iota
+	CliIntentRenderWidget              = iota
 )
 
 type CliOptions struct {
 	Intent     CliIntent
 	ConfigPath string
+	WidgetPath string
 }
 
 func ParseCliOptions() (*CliOptions, error) {
 	flags := flag.NewFlagSet("", flag.ExitOnError)
 
 	checkConfig := flags.Bool("check-config", false, "Check whether the config is valid")
+	testConnections := flags.Bool("test-connections", false, "Check whether the services used by widgets are reachable with the configured credentials")
+	renderWidget := flags.String("render-widget", "", "Render the widget defined in the given file and print its HTML without starting the server")
 	configPath := flags.String("config", "glance.yml", "Set config path")
 
 	err := flags.Parse(os.Args[1:])
@@ -33,10 +38,15 @@ func ParseCliOptions() (*CliOptions, error) {
 
 	if *checkConfig {
 		intent = CliIntentCheckConfig
+	} else if *testConnections {
+		intent = CliIntentTestConnections
+	} else if *renderWidget != "" {
+		intent = CliIntentRenderWidget
 	}
 
 	return &CliOptions{
 		Intent:     intent,
 		ConfigPath: *configPath,
+		WidgetPath: *renderWidget,
 	}, nil
 }
@@ -4,14 +4,17 @@ import (
 	"fmt"
 	"io"
 
+	"github.com/glanceapp/glance/internal/widget"
+
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Server   Server   `yaml:"server"`
-	Theme    Theme    `yaml:"theme"`
-	Branding Branding `yaml:"branding"`
-	Pages    []Page   `yaml:"pages"`
+	Server      Server             `yaml:"server"`
+	Theme       Theme              `yaml:"theme"`
+	Branding    Branding           `yaml:"branding"`
+	ArrDefaults widget.ArrDefaults `yaml:"arr-defaults"`
+	Pages       []Page             `yaml:"pages"`
 }
 
 func NewConfigFromYml(contents io.Reader) (*Config, error) {
@@ -35,8 +38,10 @@ func NewConfigFromYml(contents io.Reader) (*Config, error) {
 
 	for p := range config.Pages {
 		for c := range config.Pages[p].Columns {
+			widget.ApplyArrDefaults(config.Pages[p].Columns[c].Widgets, &config.ArrDefaults)
+
 			for w := range config.Pages[p].Columns[c].Widgets {
-				if err := config.Pages[p].Columns[c].Widgets[w].Initialize(); err != nil {
+				if err := widget.Initialize(config.Pages[p].Columns[c].Widgets[w]); err != nil {
 					return nil, err
 				}
 			}
@@ -3,18 +3,22 @@ package glance
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"html/template"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
 	"github.com/glanceapp/glance/internal/widget"
 )
 
@@ -22,11 +26,22 @@ var buildVersion = "dev"
 
 var sequentialWhitespacePattern = regexp.MustCompile(`\s+`)
 
+const defaultMaxConcurrentUpdates = 4
+
 type Application struct {
-	Version    string
-	Config     Config
-	slugToPage map[string]*Page
-	widgetByID map[uint64]widget.Widget
+	Version      string
+	Config       Config
+	slugToPage   map[string]*Page
+	widgetByID   map[uint64]widget.Widget
+	widgetToPage map[uint64]*Page
+	// guarded by the lock of the widget's page
+	widgetRefreshedAt map[uint64]time.Time
+	// limits how many widgets get updated at once across all pages so that loading a
+	// dashboard with many widgets doesn't hit every service at the same time, nil
+	// when there's no limit
+	widgetUpdateSlots chan struct{}
+	// nil unless metrics are enabled
+	metrics *metricsRegistry
 }
 
 type Theme struct {
@@ -41,12 +56,15 @@ type Theme struct {
 }
 
 type Server struct {
-	Host       string    `yaml:"host"`
-	Port       uint16    `yaml:"port"`
-	AssetsPath string    `yaml:"assets-path"`
-	BaseURL    string    `yaml:"base-url"`
-	AssetsHash string    `yaml:"-"`
-	StartedAt  time.Time `yaml:"-"` // used in custom css file
+	Host                 string    `yaml:"host"`
+	Port                 uint16    `yaml:"port"`
+	AssetsPath           string    `yaml:"assets-path"`
+	BaseURL              string    `yaml:"base-url"`
+	ExposeWidgetData     bool      `yaml:"expose-widget-data"`
+	Metrics              bool      `yaml:"metrics"`
+	MaxConcurrentUpdates int       `yaml:"max-concurrent-updates"`
+	AssetsHash           string    `yaml:"-"`
+	StartedAt            time.Time `yaml:"-"` // used in custom css file
 }
 
 type Branding struct {
@@ -78,7 +96,7 @@ type Page struct {
 	mu                    sync.Mutex
 }
 
-func (p *Page) UpdateOutdatedWidgets() {
+func (p *Page) UpdateOutdatedWidgets(app *Application) {
 	now := time.Now()
 
 	var wg sync.WaitGroup
@@ -88,14 +106,10 @@ func (p *Page) UpdateOutdatedWidgets() {
 		for w := range p.Columns[c].Widgets {
 			widget := p.Columns[c].Widgets[w]
 
-			if !widget.RequiresUpdate(&now) {
-				continue
-			}
-
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				widget.Update(context)
+				app.updateWidgetIfRequired(context, widget, &now)
 			}()
 		}
 	}
@@ -103,6 +117,32 @@ func (p *Page) UpdateOutdatedWidgets() {
 	wg.Wait()
 }
 
+func (a *Application) updateWidgetIfRequired(ctx context.Context, w widget.Widget, now *time.Time) {
+	if !w.RequiresUpdate(now) {
+		if a.metrics != nil {
+			a.metrics.recordWidgetCacheHit(w)
+		}
+
+		return
+	}
+
+	if a.widgetUpdateSlots != nil {
+		select {
+		case a.widgetUpdateSlots <- struct{}{}:
+			defer func() { <-a.widgetUpdateSlots }()
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	start := time.Now()
+	widget.UpdateWithDeadline(ctx, w)
+
+	if a.metrics != nil {
+		a.metrics.recordWidgetUpdate(w, time.Since(start))
+	}
+}
+
 // TODO: fix, currently very simple, lots of uncovered edge cases
 func titleToSlug(s string) string {
 	s = strings.ToLower(s)
@@ -125,18 +165,37 @@ func NewApplication(config *Config) (*Application, error) {
 		return nil, fmt.Errorf("no pages configured")
 	}
 
+	// set before the config is copied into the application so that both see it
+	if config.Server.MaxConcurrentUpdates == 0 {
+		config.Server.MaxConcurrentUpdates = defaultMaxConcurrentUpdates
+	}
+
 	app := &Application{
-		Version:    buildVersion,
-		Config:     *config,
-		slugToPage: make(map[string]*Page),
-		widgetByID: make(map[uint64]widget.Widget),
+		Version:      buildVersion,
+		Config:       *config,
+		slugToPage:   make(map[string]*Page),
+		widgetByID:   make(map[uint64]widget.Widget),
+		widgetToPage: make(map[uint64]*Page),
+
+		widgetRefreshedAt: make(map[uint64]time.Time),
 	}
 
 	app.Config.Server.AssetsHash = assets.PublicFSHash
+	feed.GlanceUserAgent = "glance/" + buildVersion
 	app.slugToPage[""] = &config.Pages[0]
 
+	if config.Server.MaxConcurrentUpdates > 0 {
+		app.widgetUpdateSlots = make(chan struct{}, config.Server.MaxConcurrentUpdates)
+	}
+
+	if config.Server.Metrics {
+		app.metrics = newMetricsRegistry()
+		feed.RequestObserver = app.metrics.recordRequest
+	}
+
 	providers := &widget.Providers{
 		AssetResolver: app.AssetPath,
+		APIResolver:   app.APIPath,
 	}
 
 	for p := range config.Pages {
@@ -149,7 +208,7 @@ func NewApplication(config *Config) (*Application, error) {
 		for c := range config.Pages[p].Columns {
 			for w := range config.Pages[p].Columns[c].Widgets {
 				widget := config.Pages[p].Columns[c].Widgets[w]
-				app.widgetByID[widget.GetID()] = widget
+				app.registerWidget(widget, &config.Pages[p])
 
 				widget.SetProviders(providers)
 			}
@@ -211,7 +270,7 @@ func (a *Application) HandlePageContentRequest(w http.ResponseWriter, r *http.Re
 
 	page.mu.Lock()
 	defer page.mu.Unlock()
-	page.UpdateOutdatedWidgets()
+	page.UpdateOutdatedWidgets(a)
 
 	var responseBytes bytes.Buffer
 	err := assets.PageContentTemplate.Execute(&responseBytes, pageData)
@@ -225,6 +284,130 @@ func (a *Application) HandlePageContentRequest(w http.ResponseWriter, r *http.Re
 	w.Write(responseBytes.Bytes())
 }
 
+// widgets within groups are registered as well so that they can handle requests
+func (a *Application) registerWidget(w widget.Widget, page *Page) {
+	a.widgetByID[w.GetID()] = w
+	a.widgetToPage[w.GetID()] = page
+
+	if group, ok := w.(*widget.Group); ok {
+		for _, child := range group.Widgets {
+			a.registerWidget(child, page)
+		}
+	}
+}
+
+func (a *Application) HandleWidgetDataRequest(w http.ResponseWriter, r *http.Request) {
+	widgetID, err := strconv.ParseUint(r.PathValue("widget"), 10, 64)
+
+	if err != nil {
+		a.HandleNotFound(w, r)
+		return
+	}
+
+	requestedWidget, exists := a.widgetByID[widgetID]
+
+	if !exists {
+		a.HandleNotFound(w, r)
+		return
+	}
+
+	dataProvider, ok := requestedWidget.(widget.DataProvider)
+
+	if !ok {
+		http.Error(w, "widget does not expose any data", http.StatusNotFound)
+		return
+	}
+
+	// the page's lock is held during updates, also update the widget in case
+	// its page hasn't been loaded in a while so that the data isn't stale, the
+	// request's context isn't used since a client disconnecting mid-update
+	// would otherwise leave the widget with a canceled error until its next update
+	page := a.widgetToPage[widgetID]
+	page.mu.Lock()
+
+	now := time.Now()
+	a.updateWidgetIfRequired(context.Background(), requestedWidget, &now)
+
+	responseBytes, err := json.Marshal(struct {
+		Type string `json:"type"`
+		Data any    `json:"data"`
+	}{
+		Type: requestedWidget.GetType(),
+		Data: dataProvider.Data(),
+	})
+
+	page.mu.Unlock()
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(responseBytes)
+}
+
+// keeps the refresh endpoint from being used to hammer the services behind a widget
+const widgetRefreshMinInterval = 10 * time.Second
+
+// browsers send Sec-Fetch-Site with every request and older ones at least send an
+// Origin with POST requests, so a request with neither didn't come from the dashboard
+func isSameOriginRequest(r *http.Request) bool {
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" {
+		return site == "same-origin" || site == "none"
+	}
+
+	origin := r.Header.Get("Origin")
+
+	if origin == "" {
+		return false
+	}
+
+	parsed, err := url.Parse(origin)
+
+	return err == nil && parsed.Host == r.Host
+}
+
+func (a *Application) HandleWidgetRefreshRequest(w http.ResponseWriter, r *http.Request) {
+	widgetID, err := strconv.ParseUint(r.PathValue("widget"), 10, 64)
+
+	if err != nil {
+		a.HandleNotFound(w, r)
+		return
+	}
+
+	requestedWidget, exists := a.widgetByID[widgetID]
+
+	if !exists {
+		a.HandleNotFound(w, r)
+		return
+	}
+
+	if !isSameOriginRequest(r) {
+		http.Error(w, "widgets can only be refreshed from the dashboard itself", http.StatusForbidden)
+		return
+	}
+
+	page := a.widgetToPage[widgetID]
+	page.mu.Lock()
+
+	now := time.Now()
+
+	if now.Sub(a.widgetRefreshedAt[widgetID]) >= widgetRefreshMinInterval {
+		a.widgetRefreshedAt[widgetID] = now
+		requestedWidget.Invalidate()
+	}
+
+	// same as with the data endpoint, the update isn't tied to the request, nothing
+	// is rendered since the dashboard reloads the page to show the new content
+	a.updateWidgetIfRequired(context.Background(), requestedWidget, &now)
+
+	page.mu.Unlock()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (a *Application) HandleNotFound(w http.ResponseWriter, r *http.Request) {
 	// TODO: add proper not found page
 	w.WriteHeader(http.StatusNotFound)
@@ -251,20 +434,35 @@ func (a *Application) HandleWidgetRequest(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	widget, exists := a.widgetByID[widgetID]
+	requestedWidget, exists := a.widgetByID[widgetID]
 
 	if !exists {
 		a.HandleNotFound(w, r)
 		return
 	}
 
-	widget.HandleRequest(w, r)
+	// same as with the data endpoint, the page's lock is held during the update
+	if handler, ok := requestedWidget.(widget.UpdatingRequestHandler); ok && handler.RequestRequiresUpdate(r) {
+		page := a.widgetToPage[widgetID]
+		page.mu.Lock()
+
+		now := time.Now()
+		a.updateWidgetIfRequired(context.Background(), requestedWidget, &now)
+
+		page.mu.Unlock()
+	}
+
+	requestedWidget.HandleRequest(w, r)
 }
 
 func (a *Application) AssetPath(asset string) string {
 	return a.Config.Server.BaseURL + "/static/" + a.Config.Server.AssetsHash + "/" + asset
 }
 
+func (a *Application) APIPath(path string) string {
+	return a.Config.Server.BaseURL + "/api/" + path
+}
+
 func (a *Application) Serve() error {
 	// TODO: add gzip support, static files must have their gzipped contents cached
 	// TODO: add HTTPS support
@@ -274,7 +472,31 @@ func (a *Application) Serve() error {
 	mux.HandleFunc("GET /{page}", a.HandlePageRequest)
 
 	mux.HandleFunc("GET /api/pages/{page}/content/{$}", a.HandlePageContentRequest)
+	mux.HandleFunc("POST /api/widgets/{widget}/refresh", a.HandleWidgetRefreshRequest)
 	mux.HandleFunc("/api/widgets/{widget}/{path...}", a.HandleWidgetRequest)
+
+	if a.Config.Server.ExposeWidgetData {
+		mux.HandleFunc("GET /api/widgets/{widget}/data", a.HandleWidgetDataRequest)
+
+		widgetIDs := make([]uint64, 0, len(a.widgetByID))
+
+		for id := range a.widgetByID {
+			widgetIDs = append(widgetIDs, id)
+		}
+
+		slices.Sort(widgetIDs)
+
+		for _, id := range widgetIDs {
+			if _, ok := a.widgetByID[id].(widget.DataProvider); ok {
+				slog.Info("Exposing widget data", "type", a.widgetByID[id].GetType(), "path", fmt.Sprintf("/api/widgets/%d/data", id))
+			}
+		}
+	}
+
+	if a.Config.Server.Metrics {
+		mux.HandleFunc("GET /metrics", a.HandleMetricsRequest)
+	}
+
 	mux.HandleFunc("GET /api/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
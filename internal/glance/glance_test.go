@@ -0,0 +1,92 @@
+package glance
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCalendarRequestUpdatesWidgetWithoutPageLoad(t *testing.T) {
+	var calendarRequests atomic.Int32
+
+	sonarr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/calendar" {
+			http.NotFound(w, r)
+			return
+		}
+
+		calendarRequests.Add(1)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `[{
+			"seasonNumber": 1,
+			"episodeNumber": 2,
+			"title": "The One Today",
+			"airDateUtc": %q,
+			"series": {"title": "Some Show", "titleSlug": "some-show"}
+		}]`, time.Now().UTC().Format(time.RFC3339))
+	}))
+	defer sonarr.Close()
+
+	config, err := NewConfigFromYml(strings.NewReader(fmt.Sprintf(`
+pages:
+  - name: Home
+    columns:
+      - size: full
+        widgets:
+          - type: arr-releases
+            calendar-token: secret
+            instances:
+              - service: sonarr
+                url: %s
+                api-key: key
+`, sonarr.URL)))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := NewApplication(config)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	widgetID := config.Pages[0].Columns[0].Widgets[0].GetID()
+
+	requestCalendar := func(token string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest("GET", "/api/widgets/"+strconv.FormatUint(widgetID, 10)+"/calendar.ics?token="+token, nil)
+		request.SetPathValue("widget", strconv.FormatUint(widgetID, 10))
+		request.SetPathValue("path", "calendar.ics")
+
+		recorder := httptest.NewRecorder()
+		app.HandleWidgetRequest(recorder, request)
+
+		return recorder
+	}
+
+	if recorder := requestCalendar("wrong"); recorder.Code != http.StatusUnauthorized || calendarRequests.Load() != 0 {
+		t.Fatalf("expected an unauthorized request to not update the widget, got status %d and %d requests", recorder.Code, calendarRequests.Load())
+	}
+
+	recorder := requestCalendar("secret")
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", recorder.Code)
+	}
+
+	if !strings.Contains(recorder.Body.String(), "SUMMARY:Some Show S01E02") {
+		t.Errorf("expected the calendar to contain the episode, got %s", recorder.Body.String())
+	}
+
+	requestCalendar("secret")
+
+	if calendarRequests.Load() != 1 {
+		t.Errorf("expected the cached releases to be served the second time, got %d requests", calendarRequests.Load())
+	}
+}
@@ -1,8 +1,14 @@
 package glance
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+
+	"github.com/glanceapp/glance/internal/widget"
+
+	"gopkg.in/yaml.v3"
 )
 
 func Main() int {
@@ -13,6 +19,11 @@ func Main() int {
 		return 1
 	}
 
+	// doesn't need a config so that widgets can be tried out on their own
+	if options.Intent == CliIntentRenderWidget {
+		return renderWidget(options.WidgetPath)
+	}
+
 	configFile, err := os.Open(options.ConfigPath)
 
 	if err != nil {
@@ -28,6 +39,10 @@ func Main() int {
 		return 1
 	}
 
+	if options.Intent == CliIntentTestConnections {
+		return testConnections(config)
+	}
+
 	if options.Intent == CliIntentServe {
 		app, err := NewApplication(config)
 
@@ -44,3 +59,119 @@ func Main() int {
 
 	return 0
 }
+
+func collectConnectionTesters(widgets widget.Widgets) []widget.ConnectionTester {
+	var testers []widget.ConnectionTester
+
+	for _, w := range widgets {
+		if group, ok := w.(*widget.Group); ok {
+			testers = append(testers, collectConnectionTesters(group.Widgets)...)
+		} else if tester, ok := w.(widget.ConnectionTester); ok {
+			testers = append(testers, tester)
+		}
+	}
+
+	return testers
+}
+
+func testConnections(config *Config) int {
+	var testers []widget.ConnectionTester
+
+	for p := range config.Pages {
+		for c := range config.Pages[p].Columns {
+			testers = append(testers, collectConnectionTesters(config.Pages[p].Columns[c].Widgets)...)
+		}
+	}
+
+	if len(testers) == 0 {
+		fmt.Println("no widgets with connections to test")
+		return 0
+	}
+
+	failed := 0
+
+	for _, tester := range testers {
+		for _, result := range tester.TestConnections() {
+			if result.Err != nil {
+				failed++
+				fmt.Printf("FAIL %s: %v\n", result.Name, result.Err)
+			} else {
+				fmt.Printf("OK   %s: %s\n", result.Name, result.Details)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// accepts either a single widget or a list containing one, so that widgets can be
+// copied as they are from under a column's widgets
+func widgetFromYml(contents []byte) (widget.Widget, error) {
+	var document yaml.Node
+
+	if err := yaml.Unmarshal(contents, &document); err != nil {
+		return nil, err
+	}
+
+	if len(document.Content) == 0 {
+		return nil, errors.New("no widget found")
+	}
+
+	node := document.Content[0]
+
+	if node.Kind == yaml.MappingNode {
+		node = &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{node}}
+	}
+
+	var widgets widget.Widgets
+
+	if err := node.Decode(&widgets); err != nil {
+		return nil, err
+	}
+
+	if len(widgets) != 1 {
+		return nil, fmt.Errorf("expected a single widget, found %d", len(widgets))
+	}
+
+	return widgets[0], nil
+}
+
+func renderWidget(path string) int {
+	contents, err := os.ReadFile(path)
+
+	if err != nil {
+		fmt.Printf("failed reading widget file: %v\n", err)
+		return 1
+	}
+
+	w, err := widgetFromYml(contents)
+
+	if err != nil {
+		fmt.Printf("failed parsing widget file: %v\n", err)
+		return 1
+	}
+
+	if err := widget.Initialize(w); err != nil {
+		fmt.Printf("failed initializing widget: %v\n", err)
+		return 1
+	}
+
+	w.SetProviders(&widget.Providers{
+		AssetResolver: func(asset string) string { return "/static/" + asset },
+		APIResolver:   func(path string) string { return "/api/" + path },
+	})
+
+	widget.UpdateWithDeadline(context.Background(), w)
+	fmt.Println(w.Render())
+
+	if err := w.GetError(); err != nil {
+		fmt.Printf("widget failed to update: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
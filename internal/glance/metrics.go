@@ -0,0 +1,205 @@
+package glance
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glanceapp/glance/internal/widget"
+)
+
+// upper bounds in seconds, fetches from self-hosted services usually take
+// well under a second while slow external APIs can take several
+var metricsDurationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type durationHistogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (h *durationHistogram) observe(duration time.Duration) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(metricsDurationBuckets))
+	}
+
+	seconds := duration.Seconds()
+
+	for i, bucket := range metricsDurationBuckets {
+		if seconds <= bucket {
+			h.counts[i]++
+		}
+	}
+
+	h.count++
+	h.sum += seconds
+}
+
+func (h *durationHistogram) write(w io.Writer, name string, labels string) {
+	for i, bucket := range metricsDurationBuckets {
+		var count uint64
+
+		if h.counts != nil {
+			count = h.counts[i]
+		}
+
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, strconv.FormatFloat(bucket, 'f', -1, 64), count)
+	}
+
+	fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", name, labels, strconv.FormatFloat(h.sum, 'f', -1, 64))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, h.count)
+}
+
+type widgetMetrics struct {
+	widgetType string
+	updates    durationHistogram
+	failures   uint64
+	cacheHits  uint64
+}
+
+type hostMetrics struct {
+	requests durationHistogram
+	failures uint64
+}
+
+type metricsRegistry struct {
+	mu      sync.Mutex
+	widgets map[uint64]*widgetMetrics
+	hosts   map[string]*hostMetrics
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		widgets: make(map[uint64]*widgetMetrics),
+		hosts:   make(map[string]*hostMetrics),
+	}
+}
+
+func (m *metricsRegistry) recordWidgetUpdate(w widget.Widget, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.widgets[w.GetID()]
+
+	if !exists {
+		entry = &widgetMetrics{widgetType: w.GetType()}
+		m.widgets[w.GetID()] = entry
+	}
+
+	entry.updates.observe(duration)
+
+	if w.GetError() != nil {
+		entry.failures++
+	}
+}
+
+// only widgets that have been updated before are counted so that the ones
+// which never need updating, like the clock, don't skew the hit rate
+func (m *metricsRegistry) recordWidgetCacheHit(w widget.Widget) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry, exists := m.widgets[w.GetID()]; exists {
+		entry.cacheHits++
+	}
+}
+
+func (m *metricsRegistry) recordRequest(host string, duration time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.hosts[host]
+
+	if !exists {
+		entry = &hostMetrics{}
+		m.hosts[host] = entry
+	}
+
+	entry.requests.observe(duration)
+
+	if failed {
+		entry.failures++
+	}
+}
+
+var metricsLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// writes the metrics in the Prometheus text exposition format
+func (m *metricsRegistry) writeTo(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	widgetIDs := make([]uint64, 0, len(m.widgets))
+
+	for id := range m.widgets {
+		widgetIDs = append(widgetIDs, id)
+	}
+
+	slices.Sort(widgetIDs)
+
+	widgetLabels := func(id uint64) string {
+		return fmt.Sprintf(`widget_id="%d",widget_type="%s"`, id, metricsLabelEscaper.Replace(m.widgets[id].widgetType))
+	}
+
+	fmt.Fprintln(w, "# HELP glance_widget_update_duration_seconds How long updating a widget took, including fetching its data.")
+	fmt.Fprintln(w, "# TYPE glance_widget_update_duration_seconds histogram")
+
+	for _, id := range widgetIDs {
+		m.widgets[id].updates.write(w, "glance_widget_update_duration_seconds", widgetLabels(id))
+	}
+
+	fmt.Fprintln(w, "# HELP glance_widget_updates_total The number of times a widget was updated, by whether the update failed.")
+	fmt.Fprintln(w, "# TYPE glance_widget_updates_total counter")
+
+	for _, id := range widgetIDs {
+		entry := m.widgets[id]
+		fmt.Fprintf(w, "glance_widget_updates_total{%s,result=\"success\"} %d\n", widgetLabels(id), entry.updates.count-entry.failures)
+		fmt.Fprintf(w, "glance_widget_updates_total{%s,result=\"failure\"} %d\n", widgetLabels(id), entry.failures)
+	}
+
+	fmt.Fprintln(w, "# HELP glance_widget_cache_hits_total The number of times a widget was loaded without needing to be updated.")
+	fmt.Fprintln(w, "# TYPE glance_widget_cache_hits_total counter")
+
+	for _, id := range widgetIDs {
+		fmt.Fprintf(w, "glance_widget_cache_hits_total{%s} %d\n", widgetLabels(id), m.widgets[id].cacheHits)
+	}
+
+	hosts := make([]string, 0, len(m.hosts))
+
+	for host := range m.hosts {
+		hosts = append(hosts, host)
+	}
+
+	slices.Sort(hosts)
+
+	hostLabels := func(host string) string {
+		return fmt.Sprintf(`host="%s"`, metricsLabelEscaper.Replace(host))
+	}
+
+	fmt.Fprintln(w, "# HELP glance_feed_request_duration_seconds How long requests made while fetching data took, by the host they were made to.")
+	fmt.Fprintln(w, "# TYPE glance_feed_request_duration_seconds histogram")
+
+	for _, host := range hosts {
+		m.hosts[host].requests.write(w, "glance_feed_request_duration_seconds", hostLabels(host))
+	}
+
+	fmt.Fprintln(w, "# HELP glance_feed_requests_total The number of requests made while fetching data, by the host they were made to and whether they failed.")
+	fmt.Fprintln(w, "# TYPE glance_feed_requests_total counter")
+
+	for _, host := range hosts {
+		entry := m.hosts[host]
+		fmt.Fprintf(w, "glance_feed_requests_total{%s,result=\"success\"} %d\n", hostLabels(host), entry.requests.count-entry.failures)
+		fmt.Fprintf(w, "glance_feed_requests_total{%s,result=\"failure\"} %d\n", hostLabels(host), entry.failures)
+	}
+}
+
+func (a *Application) HandleMetricsRequest(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	a.metrics.writeTo(w)
+}
@@ -0,0 +1,1108 @@
+package widget
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"html/template"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+// the options for connecting to Sonarr, Radarr or Lidarr, shared by all of the
+// widgets that use them so that their configuration can be copied between them
+type arrConnectionConfig struct {
+	Enable        *bool             `yaml:"enable"`
+	URL           OptionalEnvString `yaml:"url"`
+	LinkBase      OptionalEnvString `yaml:"link-base"`
+	URLBase       string            `yaml:"url-base"`
+	APIKey        OptionalEnvString `yaml:"api-key"`
+	AllowInsecure bool              `yaml:"allow-insecure"`
+	UserAgent     string            `yaml:"user-agent"`
+	Headers       HeadersField      `yaml:"headers"`
+}
+
+func (config *arrConnectionConfig) IsEnabled() bool {
+	return config.Enable == nil || *config.Enable
+}
+
+func validatePosterURLTemplate(template string, usedBy string) error {
+	if template != "" && !strings.Contains(template, "{remoteUrl}") && !strings.Contains(template, "{remoteUrlEncoded}") {
+		return fmt.Errorf("poster-url-template for %s must contain either {remoteUrl} or {remoteUrlEncoded}", usedBy)
+	}
+
+	return nil
+}
+
+// defaults to poster so that templates can use it as a class
+func validateImageType(imageType *string, usedBy string) error {
+	if *imageType == "" {
+		*imageType = "poster"
+	}
+
+	if *imageType != "poster" && *imageType != "fanart" && *imageType != "banner" {
+		return fmt.Errorf("invalid image-type '%s' for %s, must be either poster, fanart or banner", *imageType, usedBy)
+	}
+
+	return nil
+}
+
+func validateThumbnailSize(size int, usedBy string) error {
+	if size != 0 && !slices.Contains(feed.ArrThumbnailSizes, size) {
+		return fmt.Errorf("invalid thumbnail-size %d for %s, must be one of 185, 342, 500 or 780", size, usedBy)
+	}
+
+	return nil
+}
+
+// the aspect ratios match those in the stylesheet, while the default widths are roughly
+// what the stylesheet's widths in rem come out to with the default font size
+var arrImageTypeDimensions = map[string]struct{ width, height, defaultWidth int }{
+	"poster": {2, 3, 64},
+	"fanart": {16, 9, 128},
+	"banner": {758, 140, 192},
+}
+
+// the size that images are shown at, passed to the templates so that the space for
+// them is reserved before they load rather than the list shifting as they come in
+type arrPosterSize struct {
+	Width  int
+	Height int
+	// whether the width was set from the config, otherwise it's left to the stylesheet
+	Fixed bool
+}
+
+type arrPoster struct {
+	URL string
+	arrPosterSize
+}
+
+// called from the templates for each of the images
+func (size arrPosterSize) Poster(url string) arrPoster {
+	return arrPoster{URL: url, arrPosterSize: size}
+}
+
+// expects the image type to have been validated already
+func newArrPosterSize(imageType string, width int, usedBy string) (arrPosterSize, error) {
+	if width < 0 {
+		return arrPosterSize{}, fmt.Errorf("poster-width for %s must be 0 or greater, got %d", usedBy, width)
+	}
+
+	dimensions := arrImageTypeDimensions[imageType]
+	size := arrPosterSize{Width: width, Fixed: width > 0}
+
+	if !size.Fixed {
+		size.Width = dimensions.defaultWidth
+	}
+
+	size.Height = int(math.Round(float64(size.Width) * float64(dimensions.height) / float64(dimensions.width)))
+
+	return size, nil
+}
+
+// compiled from the include-title-regex and exclude-title-regex options, which filter
+// releases by the title of their series, movie or artist once they've been fetched
+type arrTitleFilter struct {
+	include *regexp.Regexp
+	exclude *regexp.Regexp
+}
+
+func newArrTitleFilter(include, exclude string, usedBy string) (arrTitleFilter, error) {
+	var filter arrTitleFilter
+	var err error
+
+	if include != "" {
+		if filter.include, err = regexp.Compile(include); err != nil {
+			return filter, fmt.Errorf("invalid include-title-regex for %s: %v", usedBy, err)
+		}
+	}
+
+	if exclude != "" {
+		if filter.exclude, err = regexp.Compile(exclude); err != nil {
+			return filter, fmt.Errorf("invalid exclude-title-regex for %s: %v", usedBy, err)
+		}
+	}
+
+	return filter, nil
+}
+
+func (filter arrTitleFilter) matches(title string) bool {
+	if filter.include != nil && !filter.include.MatchString(title) {
+		return false
+	}
+
+	return filter.exclude == nil || !filter.exclude.MatchString(title)
+}
+
+func (filter arrTitleFilter) apply(releases feed.ArrReleases) feed.ArrReleases {
+	if filter.include == nil && filter.exclude == nil {
+		return releases
+	}
+
+	return slices.DeleteFunc(releases, func(release feed.ArrRelease) bool {
+		return !filter.matches(release.Title)
+	})
+}
+
+type localeDateLayouts struct {
+	short  string
+	full   string
+	hour24 bool
+}
+
+// month and day names are always in English, so numeric layouts are used for
+// locales that don't put the month before the day like English does
+var dateLayoutsByLocale = map[string]localeDateLayouts{
+	"en-us": {"Jan 2", "Jan 2, 2006", false},
+	"en-gb": {"2 Jan", "2 Jan 2006", true},
+	"en-au": {"2 Jan", "2 Jan 2006", false},
+	"en-ie": {"2 Jan", "2 Jan 2006", true},
+	"en-nz": {"2 Jan", "2 Jan 2006", false},
+	"de":    {"2.1.", "2.1.2006", true},
+	"da":    {"2.1.", "2.1.2006", true},
+	"fi":    {"2.1.", "2.1.2006", true},
+	"nb":    {"2.1.", "2.1.2006", true},
+	"pl":    {"02.01", "02.01.2006", true},
+	"ru":    {"02.01", "02.01.2006", true},
+	"fr":    {"02/01", "02/01/2006", true},
+	"es":    {"02/01", "02/01/2006", true},
+	"it":    {"02/01", "02/01/2006", true},
+	"pt":    {"02/01", "02/01/2006", true},
+	"nl":    {"2-1", "2-1-2006", true},
+	"sv":    {"2/1", "2006-01-02", true},
+	"ja":    {"1/2", "2006/1/2", true},
+	"zh":    {"1/2", "2006/1/2", true},
+	"ko":    {"1. 2.", "2006. 1. 2.", false},
+}
+
+// looks up the locale as is first and then only by its language, so that e.g. de-AT
+// uses the German layouts, an empty locale is the same as en-US
+func getLocaleDateLayouts(locale string, usedBy string) (localeDateLayouts, error) {
+	if locale == "" {
+		return dateLayoutsByLocale["en-us"], nil
+	}
+
+	normalized := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+
+	if layouts, exists := dateLayoutsByLocale[normalized]; exists {
+		return layouts, nil
+	}
+
+	language, _, _ := strings.Cut(normalized, "-")
+
+	if language == "en" {
+		return dateLayoutsByLocale["en-us"], nil
+	}
+
+	if layouts, exists := dateLayoutsByLocale[language]; exists {
+		return layouts, nil
+	}
+
+	return localeDateLayouts{}, fmt.Errorf("unsupported locale '%s' for %s", locale, usedBy)
+}
+
+// the window is filtered against after fetching, so it can't be changed from the config
+func validateExtraQuery(query map[string]string, usedBy string) error {
+	for key := range query {
+		if key == "start" || key == "end" {
+			return fmt.Errorf("extra-query for %s cannot set %s", usedBy, key)
+		}
+	}
+
+	return nil
+}
+
+func validateAirDateSource(source string, usedBy string) error {
+	if source != "" && source != "utc" && source != "network" {
+		return fmt.Errorf("invalid air-date-source '%s' for %s, must be either utc or network", source, usedBy)
+	}
+
+	return nil
+}
+
+var weekdaysByName = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+func parseWeekdays(names []string, usedBy string) ([]time.Weekday, error) {
+	weekdays := make([]time.Weekday, 0, len(names))
+
+	for _, name := range names {
+		weekday, exists := weekdaysByName[strings.ToLower(strings.TrimSpace(name))]
+
+		if !exists {
+			return nil, fmt.Errorf("invalid weekday '%s' in include-weekdays for %s, must be one of mon, tue, wed, thu, fri, sat or sun", name, usedBy)
+		}
+
+		weekdays = append(weekdays, weekday)
+	}
+
+	return weekdays, nil
+}
+
+// the api key is checked here rather than left for the first request to fail
+// since a missing key otherwise shows up as a vague 401 from the instance
+func (config *arrConnectionConfig) validate(usedBy string) error {
+	if config.URL == "" {
+		return fmt.Errorf("url is required for %s", usedBy)
+	}
+
+	if config.APIKey == "" {
+		return fmt.Errorf("api-key is required for %s", usedBy)
+	}
+
+	return nil
+}
+
+func (config *arrConnectionConfig) normalizeURLs(usedBy string) error {
+	if err := config.URL.normalizeURL(usedBy); err != nil {
+		return err
+	}
+
+	return config.LinkBase.normalizeURL("the link-base of " + usedBy)
+}
+
+// the name is meant to tell apart widgets that point at different instances
+// of the same service, so it's put in front of the default title and in the logs
+func (w *widgetBase) withArrName(name string, title string) *widgetBase {
+	w.name = name
+
+	if name != "" {
+		title = name + " — " + title
+	}
+
+	return w.withTitle(title)
+}
+
+func (config *arrConnectionConfig) newRequest(source feed.ArrSource) *feed.ArrReleaseRequest {
+	return &feed.ArrReleaseRequest{
+		Source:        source,
+		URL:           string(config.URL),
+		LinkBase:      string(config.LinkBase),
+		URLBase:       config.URLBase,
+		APIKey:        string(config.APIKey),
+		AllowInsecure: config.AllowInsecure,
+		UserAgent:     config.UserAgent,
+		Headers:       config.Headers.toMap(),
+	}
+}
+
+// shared by the widgets that connect to a single instance, a disabled widget is left
+// without a cache duration so that it never gets updated and no request is returned
+func (config *arrConnectionConfig) newRequestFor(widget *widgetBase, source feed.ArrSource, cacheDuration time.Duration, usedBy string) (*feed.ArrReleaseRequest, error) {
+	if !config.IsEnabled() {
+		widget.ContentAvailable = true
+		return nil, nil
+	}
+
+	widget.withCacheDuration(cacheDuration)
+
+	if err := config.validate(usedBy); err != nil {
+		return nil, err
+	}
+
+	return config.newRequest(source), nil
+}
+
+func testArrConnection(widgetType string, request *feed.ArrReleaseRequest) ConnectionTestResult {
+	details, err := feed.CheckArrConnection(request)
+
+	return ConnectionTestResult{
+		Name:    fmt.Sprintf("%s %s (%s)", widgetType, request.Source, request.URL),
+		Details: details,
+		Err:     err,
+	}
+}
+
+func (config *arrConnectionConfig) linkURL() string {
+	if config.LinkBase != "" {
+		return string(config.LinkBase)
+	}
+
+	return string(config.URL)
+}
+
+// inherits the options that weren't set on the widget itself, allow-insecure
+// can only be turned on since there's no telling whether it was set to false
+func (config *arrConnectionConfig) inheritFrom(defaults *arrConnectionConfig) {
+	if config.URL == "" {
+		config.URL = defaults.URL
+	}
+
+	if config.LinkBase == "" {
+		config.LinkBase = defaults.LinkBase
+	}
+
+	if config.URLBase == "" {
+		config.URLBase = defaults.URLBase
+	}
+
+	if config.APIKey == "" {
+		config.APIKey = defaults.APIKey
+	}
+
+	if config.UserAgent == "" {
+		config.UserAgent = defaults.UserAgent
+	}
+
+	if config.Headers == nil {
+		config.Headers = defaults.Headers
+	}
+
+	config.AllowInsecure = config.AllowInsecure || defaults.AllowInsecure
+}
+
+// the top level arr-defaults, used by instances of the same service that
+// don't specify their own connection options
+type ArrDefaults struct {
+	Sonarr arrConnectionConfig `yaml:"sonarr"`
+	Radarr arrConnectionConfig `yaml:"radarr"`
+	Lidarr arrConnectionConfig `yaml:"lidarr"`
+}
+
+func (defaults *ArrDefaults) forService(service string) *arrConnectionConfig {
+	switch feed.ArrSource(service) {
+	case feed.ArrSourceSonarr:
+		return &defaults.Sonarr
+	case feed.ArrSourceRadarr:
+		return &defaults.Radarr
+	case feed.ArrSourceLidarr:
+		return &defaults.Lidarr
+	}
+
+	return nil
+}
+
+// must be called before the widgets get initialized
+func ApplyArrDefaults(widgets Widgets, defaults *ArrDefaults) {
+	for i := range widgets {
+		switch widget := widgets[i].(type) {
+		case *Group:
+			ApplyArrDefaults(widget.Widgets, defaults)
+		case *ArrReleases:
+			for j := range widget.Instances {
+				if serviceDefaults := defaults.forService(widget.Instances[j].Service); serviceDefaults != nil {
+					widget.Instances[j].inheritFrom(serviceDefaults)
+				}
+			}
+		case *SonarrPremieres:
+			widget.inheritFrom(&defaults.Sonarr)
+		case *SonarrStats:
+			widget.inheritFrom(&defaults.Sonarr)
+		case *SonarrCutoffUnmet:
+			widget.inheritFrom(&defaults.Sonarr)
+		case *RadarrCollections:
+			widget.inheritFrom(&defaults.Radarr)
+		}
+	}
+}
+
+type ArrReleases struct {
+	widgetBase `yaml:",inline"`
+	Instances  []struct {
+		arrConnectionConfig `yaml:",inline"`
+		Service             string            `yaml:"service"`
+		Networks            []string          `yaml:"networks"`
+		Availability        string            `yaml:"availability"`
+		ExtraQuery          map[string]string `yaml:"extra-query"`
+	} `yaml:"instances"`
+	Name              string            `yaml:"name"`
+	HourFormat        string            `yaml:"hour-format"`
+	Locale            string            `yaml:"locale"`
+	AirDateSource     string            `yaml:"air-date-source"`
+	DayOffset         int               `yaml:"day-offset"`
+	FromPreviousDays  int               `yaml:"from-previous-days"`
+	HoursAhead        int               `yaml:"hours-ahead"`
+	HoursBehind       int               `yaml:"hours-behind"`
+	DayStartHour      int               `yaml:"day-start-hour"`
+	IncludeWeekdays   []string          `yaml:"include-weekdays"`
+	OverviewLength    int               `yaml:"overview-length"`
+	CollapseAfter     int               `yaml:"collapse-after"`
+	ShowExternalIDs   bool              `yaml:"show-external-ids"`
+	WebhookToken      OptionalEnvString `yaml:"webhook-token"`
+	CalendarToken     OptionalEnvString `yaml:"calendar-token"`
+	ShowNextAiring    bool              `yaml:"show-next-airing"`
+	NextAiringSeries  []string          `yaml:"next-airing-series"`
+	NextAiringDays    int               `yaml:"next-airing-days"`
+	PosterURLTemplate string            `yaml:"poster-url-template"`
+	ProxyPosters      bool              `yaml:"proxy-posters"`
+	PosterCache       DurationField     `yaml:"poster-cache"`
+	CollapseSeasons   bool              `yaml:"collapse-seasons"`
+	ImageType         string            `yaml:"image-type"`
+	ImageSource       string            `yaml:"image-source"`
+	ThumbnailSize     int               `yaml:"thumbnail-size"`
+	PosterWidth       int               `yaml:"poster-width"`
+	IncludeTitleRegex string            `yaml:"include-title-regex"`
+	ExcludeTitleRegex string            `yaml:"exclude-title-regex"`
+	PosterSize        arrPosterSize     `yaml:"-"`
+	titleFilter       arrTitleFilter    `yaml:"-"`
+	EpisodeFormat     string            `yaml:"episode-format"`
+	GrabbedLabel      string            `yaml:"grabbed-label"`
+	MissingLabel      string            `yaml:"missing-label"`
+	MediaServer       struct {
+		Type          string            `yaml:"type"`
+		URL           OptionalEnvString `yaml:"url"`
+		APIKey        OptionalEnvString `yaml:"api-key"`
+		UserID        OptionalEnvString `yaml:"user-id"`
+		AllowInsecure bool              `yaml:"allow-insecure"`
+	} `yaml:"media-server"`
+	TimeFormat        string                    `yaml:"-"`
+	DateFormat        string                    `yaml:"-"`
+	ShowDates         bool                      `yaml:"-"`
+	NoReleasesMessage string                    `yaml:"-"`
+	Releases          feed.ArrReleases          `yaml:"-"`
+	GrabbedCount      int                       `yaml:"-"`
+	NextAiring        []feed.SonarrNextAiring   `yaml:"-"`
+	requests          []*feed.ArrReleaseRequest `yaml:"-"`
+	mediaServer       *feed.JellyfinRequest     `yaml:"-"`
+	stale             atomic.Bool               `yaml:"-"`
+	// the original URLs of the posters that can currently be proxied
+	proxiedPosters   map[string]struct{} `yaml:"-"`
+	proxiedPostersMu sync.Mutex          `yaml:"-"`
+	// the calendar is served outside of the page lock, so it gets its own copy
+	calendarReleases   feed.ArrReleases `yaml:"-"`
+	calendarReleasesMu sync.Mutex       `yaml:"-"`
+}
+
+func (widget *ArrReleases) Initialize() error {
+	if widget.FromPreviousDays < 0 {
+		return fmt.Errorf("from-previous-days for arr-releases widget must be 0 or greater, got %d", widget.FromPreviousDays)
+	}
+
+	if widget.DayStartHour < 0 || widget.DayStartHour > 23 {
+		return fmt.Errorf("day-start-hour for arr-releases widget must be between 0 and 23, got %d", widget.DayStartHour)
+	}
+
+	if widget.HoursAhead < 0 || widget.HoursBehind < 0 {
+		return errors.New("hours-ahead and hours-behind for arr-releases widget must be 0 or greater")
+	}
+
+	rolling := widget.HoursAhead > 0 || widget.HoursBehind > 0
+
+	if rolling && (widget.DayOffset != 0 || widget.FromPreviousDays != 0) {
+		return errors.New("hours-ahead and hours-behind for arr-releases widget cannot be combined with day-offset or from-previous-days")
+	}
+
+	if rolling {
+		widget.withArrName(widget.Name, "Coming Up")
+		widget.NoReleasesMessage = fmt.Sprintf("Nothing is releasing in the next %d hours", widget.HoursAhead)
+		widget.ShowDates = true
+
+		if widget.HoursBehind > 0 {
+			widget.NoReleasesMessage = "Nothing is releasing around now"
+		}
+	} else if widget.DayOffset == 0 && widget.FromPreviousDays == 0 {
+		widget.withArrName(widget.Name, "Releasing Today")
+		widget.NoReleasesMessage = "Nothing is releasing today"
+	} else {
+		widget.withArrName(widget.Name, "Releases")
+		widget.NoReleasesMessage = "Nothing is releasing on these days"
+		widget.ShowDates = true
+	}
+
+	widget.withCacheDuration(30 * time.Minute)
+
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
+	}
+
+	if widget.OverviewLength == 0 {
+		widget.OverviewLength = 140
+	}
+
+	if widget.EpisodeFormat == "" {
+		widget.EpisodeFormat = "S01E02"
+	}
+
+	if _, exists := feed.ArrEpisodeFormats[widget.EpisodeFormat]; !exists {
+		return fmt.Errorf("invalid episode-format '%s' for arr-releases widget, must be one of S01E02, 1x02 or E02", widget.EpisodeFormat)
+	}
+
+	if widget.GrabbedLabel == "" {
+		widget.GrabbedLabel = "Grabbed"
+	}
+
+	if widget.MissingLabel == "" {
+		widget.MissingLabel = "Missing"
+	}
+
+	if err := validatePosterURLTemplate(widget.PosterURLTemplate, "arr-releases widget"); err != nil {
+		return err
+	}
+
+	if widget.ImageSource == "" {
+		widget.ImageSource = "series"
+	}
+
+	if widget.ImageSource != "series" && widget.ImageSource != "episode" {
+		return fmt.Errorf("invalid image-source '%s' for arr-releases widget, must be either series or episode", widget.ImageSource)
+	}
+
+	// stills are landscape, so the image of the series they fall back to should be as well
+	if widget.ImageSource == "episode" && widget.ImageType == "" {
+		widget.ImageType = "fanart"
+	}
+
+	if err := validateImageType(&widget.ImageType, "arr-releases widget"); err != nil {
+		return err
+	}
+
+	if err := validateThumbnailSize(widget.ThumbnailSize, "arr-releases widget"); err != nil {
+		return err
+	}
+
+	size, err := newArrPosterSize(widget.ImageType, widget.PosterWidth, "arr-releases widget")
+
+	if err != nil {
+		return err
+	}
+
+	widget.PosterSize = size
+
+	titleFilter, err := newArrTitleFilter(widget.IncludeTitleRegex, widget.ExcludeTitleRegex, "arr-releases widget")
+
+	if err != nil {
+		return err
+	}
+
+	widget.titleFilter = titleFilter
+
+	if err := validateAirDateSource(widget.AirDateSource, "arr-releases widget"); err != nil {
+		return err
+	}
+
+	weekdays, err := parseWeekdays(widget.IncludeWeekdays, "arr-releases widget")
+
+	if err != nil {
+		return err
+	}
+
+	if widget.NextAiringDays <= 0 {
+		widget.NextAiringDays = 7
+	}
+
+	if widget.PosterCache == 0 {
+		widget.PosterCache = DurationField(24 * time.Hour)
+	}
+
+	dateLayouts, err := getLocaleDateLayouts(widget.Locale, "arr-releases widget")
+
+	if err != nil {
+		return err
+	}
+
+	widget.DateFormat = dateLayouts.short
+
+	if widget.HourFormat == "" && dateLayouts.hour24 {
+		widget.HourFormat = "24h"
+	}
+
+	if widget.HourFormat == "" || widget.HourFormat == "12h" {
+		widget.TimeFormat = "3:04pm"
+	} else if widget.HourFormat == "24h" {
+		widget.TimeFormat = "15:04"
+	} else {
+		return fmt.Errorf("invalid hour format '%s' for arr-releases widget, must be either 12h or 24h", widget.HourFormat)
+	}
+
+	for i := range widget.Instances {
+		instance := &widget.Instances[i]
+
+		if !instance.IsEnabled() {
+			continue
+		}
+
+		source := feed.ArrSource(instance.Service)
+
+		if source != feed.ArrSourceSonarr && source != feed.ArrSourceRadarr && source != feed.ArrSourceLidarr {
+			return fmt.Errorf("invalid service '%s' for arr-releases instance %d, must be either sonarr, radarr or lidarr", instance.Service, i+1)
+		}
+
+		if err := instance.normalizeURLs(fmt.Sprintf("arr-releases instance %d", i+1)); err != nil {
+			return err
+		}
+
+		if err := instance.validate(fmt.Sprintf("arr-releases instance %d", i+1)); err != nil {
+			return err
+		}
+
+		if err := validateExtraQuery(instance.ExtraQuery, fmt.Sprintf("arr-releases instance %d", i+1)); err != nil {
+			return err
+		}
+
+		if !slices.Contains([]string{"", "any", "announced", "inCinemas", "released"}, instance.Availability) {
+			return fmt.Errorf("invalid availability '%s' for arr-releases instance %d, must be one of any, announced, inCinemas or released", instance.Availability, i+1)
+		}
+
+		request := instance.newRequest(source)
+		request.Networks = instance.Networks
+		request.Availability = instance.Availability
+		request.ExtraQuery = instance.ExtraQuery
+		request.DayOffset = widget.DayOffset
+		request.FromPreviousDays = widget.FromPreviousDays
+		request.HoursAhead = time.Duration(widget.HoursAhead) * time.Hour
+		request.HoursBehind = time.Duration(widget.HoursBehind) * time.Hour
+		request.DayStartHour = widget.DayStartHour
+		request.Weekdays = weekdays
+		request.OverviewLength = widget.OverviewLength
+		request.PosterURLTemplate = widget.PosterURLTemplate
+		request.AirDateSource = widget.AirDateSource
+		request.CollapseSeasons = widget.CollapseSeasons
+		request.ImageType = widget.ImageType
+		request.ImageSource = widget.ImageSource
+		request.EpisodeFormat = widget.EpisodeFormat
+		request.ThumbnailSize = widget.ThumbnailSize
+
+		widget.requests = append(widget.requests, request)
+	}
+
+	if len(widget.requests) == 0 {
+		return errors.New("arr-releases widget must have at least one enabled instance")
+	}
+
+	if err := widget.initializeMediaServer(); err != nil {
+		return err
+	}
+
+	if widget.WebhookToken != "" {
+		widget.logger().Info("Webhook for arr-releases widget enabled", "path", fmt.Sprintf("/api/widgets/%d/webhook", widget.GetID()))
+	}
+
+	if widget.CalendarToken != "" {
+		widget.logger().Info("Calendar for arr-releases widget enabled", "path", fmt.Sprintf("/api/widgets/%d/calendar.ics", widget.GetID()))
+	}
+
+	return nil
+}
+
+func (widget *ArrReleases) RequiresUpdate(now *time.Time) bool {
+	return widget.stale.Load() || widget.widgetBase.RequiresUpdate(now)
+}
+
+func (widget *ArrReleases) Update(ctx context.Context) {
+	widget.stale.Store(false)
+
+	releases, err := feed.FetchReleasesFromArrStack(ctx, widget.logger(), widget.requests, time.Now())
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	releases = widget.titleFilter.apply(releases)
+	grabbed := 0
+
+	for i := range releases {
+		releases[i].SourceIconURL = widget.Providers.AssetResolver("icons/" + string(releases[i].Source) + ".svg")
+
+		if releases[i].Grabbed {
+			grabbed++
+		}
+	}
+
+	if widget.mediaServer != nil {
+		widget.markWatchedReleases(ctx, releases)
+	}
+
+	if widget.ProxyPosters {
+		widget.proxyPosters(releases)
+	}
+
+	widget.Releases = releases
+	widget.GrabbedCount = grabbed
+
+	widget.calendarReleasesMu.Lock()
+	widget.calendarReleases = releases
+	widget.calendarReleasesMu.Unlock()
+
+	if widget.ShowNextAiring {
+		widget.updateNextAiring(ctx)
+	}
+}
+
+func (widget *ArrReleases) initializeMediaServer() error {
+	server := &widget.MediaServer
+
+	if server.URL == "" {
+		return nil
+	}
+
+	if err := server.URL.normalizeURL("media-server of arr-releases widget"); err != nil {
+		return err
+	}
+
+	if server.Type == "" {
+		server.Type = "jellyfin"
+	}
+
+	if server.Type != "jellyfin" {
+		return fmt.Errorf("invalid media-server type '%s' for arr-releases widget, must be jellyfin", server.Type)
+	}
+
+	if server.APIKey == "" || server.UserID == "" {
+		return errors.New("api-key and user-id are required for the media-server of arr-releases widget")
+	}
+
+	widget.mediaServer = &feed.JellyfinRequest{
+		URL:           string(server.URL),
+		APIKey:        string(server.APIKey),
+		UserID:        string(server.UserID),
+		AllowInsecure: server.AllowInsecure,
+	}
+
+	return nil
+}
+
+// the releases are still shown when the media server can't be reached, just
+// without telling apart the episodes that have already been watched
+func (widget *ArrReleases) markWatchedReleases(ctx context.Context, releases feed.ArrReleases) {
+	var since time.Time
+
+	for i := range releases {
+		if releases[i].Source == feed.ArrSourceSonarr && (since.IsZero() || releases[i].ReleasedAt.Before(since)) {
+			since = releases[i].ReleasedAt
+		}
+	}
+
+	if since.IsZero() {
+		return
+	}
+
+	// premiere dates are stored without a time, so the episode may fall on the previous day
+	played, err := feed.FetchJellyfinPlayedEpisodes(ctx, widget.mediaServer, since.AddDate(0, 0, -1))
+
+	if err != nil {
+		widget.logger().Warn("Failed to fetch watched episodes", "error", err)
+		widget.withNotice(fmt.Errorf("couldn't get watched episodes: %w", err))
+		return
+	}
+
+	for i := range releases {
+		release := &releases[i]
+
+		// a collapsed season isn't watched until all of its episodes are, which isn't known
+		if release.Source != feed.ArrSourceSonarr || release.EpisodeCount > 0 {
+			continue
+		}
+
+		release.Watched = played.Contains(release.TVDbID, release.SeriesTVDbID, release.SeasonNumber, release.EpisodeNumber)
+	}
+}
+
+// a separate request is made for the days after the widget's window, failing
+// to get the next episodes only shows a notice rather than an error
+func (widget *ArrReleases) updateNextAiring(ctx context.Context) {
+	var requests []*feed.ArrReleaseRequest
+
+	for _, request := range widget.requests {
+		if request.Source == feed.ArrSourceSonarr {
+			requests = append(requests, request)
+		}
+	}
+
+	if len(requests) == 0 {
+		return
+	}
+
+	nextAiring, err := feed.FetchSonarrNextAiring(ctx, widget.logger(), requests, time.Now(), widget.NextAiringDays, widget.NextAiringSeries)
+
+	if err != nil {
+		widget.withNotice(err)
+
+		if !errors.Is(err, feed.ErrPartialContent) {
+			return
+		}
+	}
+
+	// series with an episode in the widget already don't need to be repeated
+	showing := make(map[string]struct{})
+
+	for i := range widget.Releases {
+		if widget.Releases[i].Source == feed.ArrSourceSonarr {
+			showing[widget.Releases[i].Title] = struct{}{}
+		}
+	}
+
+	widget.NextAiring = slices.DeleteFunc(nextAiring, func(n feed.SonarrNextAiring) bool {
+		_, exists := showing[n.Release.Title]
+		return exists || !widget.titleFilter.matches(n.Release.Title)
+	})
+}
+
+// only posters of the current releases are allowed through the proxy so that
+// it can't be used to make requests to arbitrary URLs
+func (widget *ArrReleases) proxyPosters(releases feed.ArrReleases) {
+	posters := make(map[string]struct{}, len(releases))
+
+	for i := range releases {
+		if releases[i].ImageURL == "" {
+			continue
+		}
+
+		posters[releases[i].ImageURL] = struct{}{}
+		releases[i].ImageURL = widget.Providers.APIResolver(
+			fmt.Sprintf("widgets/%d/poster?url=%s", widget.GetID(), url.QueryEscape(releases[i].ImageURL)),
+		)
+	}
+
+	widget.proxiedPostersMu.Lock()
+	widget.proxiedPosters = posters
+	widget.proxiedPostersMu.Unlock()
+}
+
+func (widget *ArrReleases) HandleRequest(w http.ResponseWriter, r *http.Request) {
+	switch r.PathValue("path") {
+	case "webhook":
+		widget.handleWebhook(w, r)
+	case "calendar.ics":
+		widget.handleCalendar(w, r)
+	case "poster":
+		widget.handlePoster(w, r)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func requestHasToken(r *http.Request, expected OptionalEnvString) bool {
+	token := r.URL.Query().Get("token")
+
+	if token == "" {
+		_, token, _ = r.BasicAuth()
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// the *arr apps can notify a webhook on grab and import, rather than parsing the payload
+// the cached releases are marked as stale so that they get refetched on the next page load
+func (widget *ArrReleases) handleWebhook(w http.ResponseWriter, r *http.Request) {
+	if widget.WebhookToken == "" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requestHasToken(r, widget.WebhookToken) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	widget.stale.Store(true)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// only authorized calendar requests update the widget, so that the feed can't be used
+// to make requests to the *arr instances without knowing the token
+func (widget *ArrReleases) RequestRequiresUpdate(r *http.Request) bool {
+	return r.PathValue("path") == "calendar.ics" && r.Method == http.MethodGet &&
+		widget.CalendarToken != "" && requestHasToken(r, widget.CalendarToken)
+}
+
+// the widget is updated beforehand if its cache has expired, so calendar apps polling
+// the feed only cause requests to the *arr instances as often as the page would
+func (widget *ArrReleases) handleCalendar(w http.ResponseWriter, r *http.Request) {
+	if widget.CalendarToken == "" {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !requestHasToken(r, widget.CalendarToken) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	widget.calendarReleasesMu.Lock()
+	releases := widget.calendarReleases
+	widget.calendarReleasesMu.Unlock()
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="releases.ics"`)
+	w.Write(arrReleasesToICalendar(releases, time.Now()))
+}
+
+func (widget *ArrReleases) handlePoster(w http.ResponseWriter, r *http.Request) {
+	if !widget.ProxyPosters {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	posterURL := r.URL.Query().Get("url")
+
+	widget.proxiedPostersMu.Lock()
+	_, exists := widget.proxiedPosters[posterURL]
+	widget.proxiedPostersMu.Unlock()
+
+	if !exists {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+
+	poster, contentType, err := feed.FetchArrPoster(posterURL)
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(time.Duration(widget.PosterCache).Seconds())))
+	w.Write(poster)
+}
+
+var iCalendarEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", "")
+
+// lines longer than 75 octets must be folded, continuation lines start with a space
+func writeICalendarLine(buf *bytes.Buffer, line string) {
+	maxLen := 75
+
+	for len(line) > maxLen {
+		cut := maxLen
+
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		maxLen = 74
+	}
+
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// episodes get a timed event starting at their air time, movies and albums only have
+// a release date so they get an all-day event instead
+func arrReleasesToICalendar(releases feed.ArrReleases, now time.Time) []byte {
+	const utcFormat = "20060102T150405Z"
+	const dateFormat = "20060102"
+
+	var buf bytes.Buffer
+
+	writeICalendarLine(&buf, "BEGIN:VCALENDAR")
+	writeICalendarLine(&buf, "VERSION:2.0")
+	writeICalendarLine(&buf, "PRODID:-//glance//arr-releases//EN")
+	writeICalendarLine(&buf, "CALSCALE:GREGORIAN")
+	writeICalendarLine(&buf, "X-WR-CALNAME:Releases")
+
+	for i := range releases {
+		release := &releases[i]
+		summary := release.Title
+		description := release.Overview
+
+		if release.EpisodeCount > 0 {
+			summary += fmt.Sprintf(" Season %d (%d episodes)", release.SeasonNumber, release.EpisodeCount)
+		} else if release.Source == feed.ArrSourceSonarr {
+			summary += " " + release.EpisodeCode
+		} else if release.ReleaseType != "" {
+			summary += " (" + release.ReleaseType + ")"
+		}
+
+		if release.Finale != "" {
+			summary += " (" + release.Finale + ")"
+		}
+
+		if release.URL != "" {
+			if description != "" {
+				description += "\n\n"
+			}
+
+			description += release.URL
+		}
+
+		uid := fmt.Sprintf(
+			"%s-%s-%d-%d-%s-%s@glance",
+			release.Source,
+			release.ReleasedAt.UTC().Format(utcFormat),
+			release.SeasonNumber,
+			release.EpisodeNumber,
+			release.ReleaseType,
+			release.Title,
+		)
+
+		writeICalendarLine(&buf, "BEGIN:VEVENT")
+		writeICalendarLine(&buf, "UID:"+iCalendarEscaper.Replace(uid))
+		writeICalendarLine(&buf, "DTSTAMP:"+now.UTC().Format(utcFormat))
+
+		if release.Source == feed.ArrSourceSonarr {
+			duration := 30 * time.Minute
+
+			if release.Runtime > 0 {
+				duration = time.Duration(release.Runtime) * time.Minute
+			}
+
+			writeICalendarLine(&buf, "DTSTART:"+release.ReleasedAt.UTC().Format(utcFormat))
+			writeICalendarLine(&buf, "DTEND:"+release.ReleasedAt.Add(duration).UTC().Format(utcFormat))
+		} else {
+			writeICalendarLine(&buf, "DTSTART;VALUE=DATE:"+release.ReleasedAt.Format(dateFormat))
+			writeICalendarLine(&buf, "DTEND;VALUE=DATE:"+release.ReleasedAt.AddDate(0, 0, 1).Format(dateFormat))
+		}
+
+		writeICalendarLine(&buf, "SUMMARY:"+iCalendarEscaper.Replace(summary))
+
+		if description != "" {
+			writeICalendarLine(&buf, "DESCRIPTION:"+iCalendarEscaper.Replace(description))
+		}
+
+		if release.URL != "" {
+			writeICalendarLine(&buf, "URL:"+release.URL)
+		}
+
+		writeICalendarLine(&buf, "END:VEVENT")
+	}
+
+	writeICalendarLine(&buf, "END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+func (widget *ArrReleases) TestConnections() []ConnectionTestResult {
+	results := make([]ConnectionTestResult, 0, len(widget.requests))
+
+	for _, request := range widget.requests {
+		results = append(results, testArrConnection(widget.GetType(), request))
+	}
+
+	return results
+}
+
+func (widget *ArrReleases) Data() any {
+	return widget.Releases
+}
+
+func (widget *ArrReleases) Render() template.HTML {
+	return widget.render(widget, assets.ArrReleasesTemplate)
+}
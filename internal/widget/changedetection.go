@@ -26,8 +26,8 @@ func (widget *ChangeDetection) Initialize() error {
 		widget.Limit = 10
 	}
 
-	if widget.CollapseAfter == 0 || widget.CollapseAfter < -1 {
-		widget.CollapseAfter = 5
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
 	}
 
 	if widget.InstanceURL == "" {
@@ -48,7 +48,7 @@ func (widget *ChangeDetection) Update(ctx context.Context) {
 		widget.WatchUUIDs = uuids
 	}
 
-	watches, err := feed.FetchWatchesFromChangeDetection(widget.InstanceURL, widget.WatchUUIDs, string(widget.Token))
+	watches, err := feed.FetchWatchesFromChangeDetection(widget.logger(), widget.InstanceURL, widget.WatchUUIDs, string(widget.Token))
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -37,6 +37,10 @@ func makeDNSTimeLabels(format string) [8]string {
 }
 
 func (widget *DNSStats) Initialize() error {
+	if err := widget.URL.normalizeURL("dns-stats widget"); err != nil {
+		return err
+	}
+
 	widget.
 		withTitle("DNS Stats").
 		withTitleURL(string(widget.URL)).
@@ -56,7 +60,7 @@ func (widget *DNSStats) Update(ctx context.Context) {
 	if widget.Service == "adguard" {
 		stats, err = feed.FetchAdguardStats(string(widget.URL), string(widget.Username), string(widget.Password))
 	} else {
-		stats, err = feed.FetchPiholeStats(string(widget.URL), string(widget.Token))
+		stats, err = feed.FetchPiholeStats(widget.logger(), string(widget.URL), string(widget.Token))
 	}
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
@@ -0,0 +1,79 @@
+package widget
+
+import (
+	"context"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type DownloadClient struct {
+	widgetBase    `yaml:",inline"`
+	Service       string                      `yaml:"service"`
+	URL           OptionalEnvString           `yaml:"url"`
+	APIKey        OptionalEnvString           `yaml:"api-key"`
+	Username      OptionalEnvString           `yaml:"username"`
+	Password      OptionalEnvString           `yaml:"password"`
+	AllowInsecure bool                        `yaml:"allow-insecure"`
+	Limit         int                         `yaml:"limit"`
+	CollapseAfter int                         `yaml:"collapse-after"`
+	Status        *feed.DownloadClientStatus  `yaml:"-"`
+	request       *feed.DownloadClientRequest `yaml:"-"`
+}
+
+func (widget *DownloadClient) Initialize() error {
+	if err := widget.URL.normalizeURL("download-client widget"); err != nil {
+		return err
+	}
+
+	widget.withTitle("Downloads").withTitleURL(string(widget.URL)).withCacheDuration(time.Minute)
+
+	if widget.Service != "sabnzbd" && widget.Service != "qbittorrent" {
+		return fmt.Errorf("invalid service '%s' for download-client widget, must be either sabnzbd or qbittorrent", widget.Service)
+	}
+
+	if widget.URL == "" {
+		return fmt.Errorf("url is required for the download-client widget")
+	}
+
+	if widget.Service == "sabnzbd" && widget.APIKey == "" {
+		return fmt.Errorf("api-key is required for the download-client widget when using sabnzbd")
+	}
+
+	if widget.Limit <= 0 {
+		widget.Limit = 10
+	}
+
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
+	}
+
+	widget.request = &feed.DownloadClientRequest{
+		Service:       widget.Service,
+		URL:           string(widget.URL),
+		APIKey:        string(widget.APIKey),
+		Username:      string(widget.Username),
+		Password:      string(widget.Password),
+		AllowInsecure: widget.AllowInsecure,
+		Limit:         widget.Limit,
+	}
+
+	return nil
+}
+
+func (widget *DownloadClient) Update(ctx context.Context) {
+	status, err := feed.FetchDownloadClientStatus(ctx, widget.request)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.Status = status
+}
+
+func (widget *DownloadClient) Render() template.HTML {
+	return widget.render(widget, assets.DownloadClientTemplate)
+}
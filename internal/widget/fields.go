@@ -3,6 +3,7 @@ package widget
 import (
 	"fmt"
 	"html/template"
+	"net/url"
 	"os"
 	"regexp"
 	"strconv"
@@ -15,6 +16,9 @@ import (
 var HSLColorPattern = regexp.MustCompile(`^(?:hsla?\()?(\d{1,3})(?: |,)+(\d{1,3})%?(?: |,)+(\d{1,3})%?\)?$`)
 var EnvFieldPattern = regexp.MustCompile(`^\${([A-Z_]+)}$`)
 
+// for secrets mounted as files, e.g. Docker and Kubernetes secrets
+var FileFieldPattern = regexp.MustCompile(`^\${file:(.+)}$`)
+
 const (
 	HSLHueMax        = 360
 	HSLSaturationMax = 100
@@ -133,6 +137,19 @@ func (f *OptionalEnvString) UnmarshalYAML(node *yaml.Node) error {
 		return err
 	}
 
+	if matches := FileFieldPattern.FindStringSubmatch(value); len(matches) == 2 {
+		contents, err := os.ReadFile(matches[1])
+
+		if err != nil {
+			return fmt.Errorf("reading secret file: %v", err)
+		}
+
+		// files usually end with a newline that isn't part of the secret
+		*f = OptionalEnvString(strings.TrimSpace(string(contents)))
+
+		return nil
+	}
+
 	matches := EnvFieldPattern.FindStringSubmatch(value)
 
 	if len(matches) != 2 {
@@ -156,6 +173,57 @@ func (f *OptionalEnvString) String() string {
 	return string(*f)
 }
 
+// urls copied from a browser often come with stray whitespace or without a scheme,
+// which otherwise only surfaces as a vague error once the first request fails
+func (f *OptionalEnvString) normalizeURL(usedBy string) error {
+	value := strings.TrimSpace(string(*f))
+
+	if value == "" {
+		*f = ""
+		return nil
+	}
+
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+
+	parsed, err := url.Parse(value)
+
+	if err != nil {
+		return fmt.Errorf("invalid url '%s' for %s: %v", value, usedBy, err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("invalid url '%s' for %s, scheme must be either http or https", value, usedBy)
+	}
+
+	if parsed.Host == "" {
+		return fmt.Errorf("invalid url '%s' for %s, missing host", value, usedBy)
+	}
+
+	*f = OptionalEnvString(value)
+
+	return nil
+}
+
+// headers added to the requests made to self-hosted services, e.g. for getting
+// through an authenticating proxy in front of them
+type HeadersField map[string]OptionalEnvString
+
+func (h HeadersField) toMap() map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+
+	headers := make(map[string]string, len(h))
+
+	for name, value := range h {
+		headers[name] = string(value)
+	}
+
+	return headers
+}
+
 func toSimpleIconIfPrefixed(icon string) (string, bool) {
 	if !strings.HasPrefix(icon, "si:") {
 		return icon, false
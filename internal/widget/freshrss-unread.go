@@ -0,0 +1,52 @@
+package widget
+
+import (
+	"context"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type FreshRSSUnread struct {
+	widgetBase               `yaml:",inline"`
+	freshRssConnectionConfig `yaml:",inline"`
+	PerGroup                 bool                      `yaml:"per-group"`
+	Unread                   *feed.FreshRssUnreadCount `yaml:"-"`
+	request                  *feed.FreshRssRequest     `yaml:"-"`
+}
+
+func (widget *FreshRSSUnread) Initialize() error {
+	widget.withTitle("FreshRSS").withCacheDuration(10 * time.Minute)
+
+	if err := widget.validate("freshrss-unread widget"); err != nil {
+		return err
+	}
+
+	widget.withTitleURL(string(widget.URL))
+
+	widget.request = widget.newRequest()
+	// only used for fetching the unread items of each group in batches
+	widget.request.Concurrency = 4
+
+	return nil
+}
+
+func (widget *FreshRSSUnread) Update(ctx context.Context) {
+	unread, err := feed.FetchFreshRssUnreadCount(ctx, widget.request, widget.PerGroup)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.Unread = unread
+}
+
+func (widget *FreshRSSUnread) Data() any {
+	return widget.Unread
+}
+
+func (widget *FreshRSSUnread) Render() template.HTML {
+	return widget.render(widget, assets.FreshRSSUnreadTemplate)
+}
@@ -0,0 +1,203 @@
+package widget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+// the options for connecting to a FreshRSS instance, which can be set either on the
+// widget itself or on each of its instances
+type freshRssConnectionConfig struct {
+	URL           OptionalEnvString `yaml:"url"`
+	Username      OptionalEnvString `yaml:"username"`
+	APIPassword   OptionalEnvString `yaml:"api-password"`
+	APIPath       string            `yaml:"api-path"`
+	AllowInsecure bool              `yaml:"allow-insecure"`
+	CACertPath    string            `yaml:"ca-cert-path"`
+	Headers       HeadersField      `yaml:"headers"`
+}
+
+func (config *freshRssConnectionConfig) validate(usedBy string) error {
+	if err := config.URL.normalizeURL(usedBy); err != nil {
+		return err
+	}
+
+	if config.URL == "" {
+		return fmt.Errorf("url is required for the %s", usedBy)
+	}
+
+	if config.Username == "" || config.APIPassword == "" {
+		return fmt.Errorf("username and api-password are required for the %s", usedBy)
+	}
+
+	if config.APIPath == "" {
+		config.APIPath = "/api/fever.php"
+	}
+
+	return nil
+}
+
+func (config *freshRssConnectionConfig) newRequest() *feed.FreshRssRequest {
+	return &feed.FreshRssRequest{
+		URL:           string(config.URL),
+		Username:      string(config.Username),
+		APIPassword:   string(config.APIPassword),
+		APIPath:       config.APIPath,
+		AllowInsecure: config.AllowInsecure,
+		CACertPath:    config.CACertPath,
+		Headers:       config.Headers.toMap(),
+	}
+}
+
+type FreshRSS struct {
+	widgetBase               `yaml:",inline"`
+	freshRssConnectionConfig `yaml:",inline"`
+	Instances                []struct {
+		freshRssConnectionConfig `yaml:",inline"`
+		Name                     string `yaml:"name"`
+	} `yaml:"instances"`
+	Style            string                  `yaml:"style"`
+	Limit            int                     `yaml:"limit"`
+	MaxAge           DurationField           `yaml:"max-age"`
+	HighlightAge     DurationField           `yaml:"highlight-age"`
+	PerFeedLimit     int                     `yaml:"per-feed-limit"`
+	IncludeKeywords  []string                `yaml:"include-keywords"`
+	ExcludeKeywords  []string                `yaml:"exclude-keywords"`
+	MatchContent     bool                    `yaml:"match-content"`
+	Concurrency      int                     `yaml:"concurrency"`
+	CollapseAfter    int                     `yaml:"collapse-after"`
+	SingleLineTitles bool                    `yaml:"single-line-titles"`
+	ShowFailedFeeds  bool                    `yaml:"show-failed-feeds"`
+	DiscoverFavicons bool                    `yaml:"discover-favicons"`
+	FailedFeeds      int                     `yaml:"-"`
+	Items            feed.RSSFeedItems       `yaml:"-"`
+	NoItemsMessage   string                  `yaml:"-"`
+	requests         []*feed.FreshRssRequest `yaml:"-"`
+}
+
+func (widget *FreshRSS) Initialize() error {
+	if widget.URL == "" && len(widget.Instances) == 0 {
+		return errors.New("either url or instances is required for the freshrss widget")
+	}
+
+	if widget.URL != "" {
+		if err := widget.freshRssConnectionConfig.validate("freshrss widget"); err != nil {
+			return err
+		}
+
+		widget.requests = append(widget.requests, widget.freshRssConnectionConfig.newRequest())
+	}
+
+	for i := range widget.Instances {
+		instance := &widget.Instances[i]
+
+		if err := instance.validate(fmt.Sprintf("freshrss instance %d", i+1)); err != nil {
+			return err
+		}
+
+		request := instance.newRequest()
+		request.Name = instance.Name
+		widget.requests = append(widget.requests, request)
+	}
+
+	// the items only need telling apart when they come from more than one place
+	if len(widget.requests) > 1 {
+		for _, request := range widget.requests {
+			if request.Name == "" {
+				request.Name = request.Username
+			}
+		}
+	}
+
+	widget.withTitle("FreshRSS").withTitleURL(widget.requests[0].URL).withCacheDuration(30 * time.Minute)
+
+	if widget.Limit == 0 || widget.Limit < -1 {
+		widget.Limit = 25
+	}
+
+	if widget.Concurrency <= 0 {
+		widget.Concurrency = 8
+	}
+
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
+	}
+
+	for _, request := range widget.requests {
+		request.Concurrency = widget.Concurrency
+		request.IsDetailed = widget.Style == "detailed-list"
+		request.MaxAge = time.Duration(widget.MaxAge)
+		request.PerFeedLimit = widget.PerFeedLimit
+		request.DiscoverFavicons = widget.DiscoverFavicons
+		request.HighlightAge = time.Duration(widget.HighlightAge)
+		// only a shortened version of the content is available to match against
+		request.IncludeDescription = widget.MatchContent
+
+		if len(widget.IncludeKeywords) > 0 || len(widget.ExcludeKeywords) > 0 {
+			request.Filter = widget.itemMatchesKeywords
+		}
+	}
+
+	for i := range widget.IncludeKeywords {
+		widget.IncludeKeywords[i] = strings.ToLower(widget.IncludeKeywords[i])
+	}
+
+	for i := range widget.ExcludeKeywords {
+		widget.ExcludeKeywords[i] = strings.ToLower(widget.ExcludeKeywords[i])
+	}
+
+	widget.NoItemsMessage = "No items were returned from FreshRSS."
+
+	return nil
+}
+
+func (widget *FreshRSS) Update(ctx context.Context) {
+	items, failed, err := feed.GetItemsFromFreshRssInstances(ctx, widget.logger(), widget.requests, widget.Limit)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.Items = items
+	widget.FailedFeeds = failed
+}
+
+// keywords are lowercased in Initialize, an item needs to contain at least one
+// of the included keywords, if there are any, and none of the excluded ones
+func (widget *FreshRSS) itemMatchesKeywords(item *feed.RSSFeedItem) bool {
+	text := strings.ToLower(item.Title)
+
+	if widget.MatchContent {
+		text += "\n" + strings.ToLower(item.Description)
+	}
+
+	containsKeyword := func(keyword string) bool {
+		return strings.Contains(text, keyword)
+	}
+
+	if len(widget.IncludeKeywords) > 0 && !slices.ContainsFunc(widget.IncludeKeywords, containsKeyword) {
+		return false
+	}
+
+	return !slices.ContainsFunc(widget.ExcludeKeywords, containsKeyword)
+}
+
+func (widget *FreshRSS) Data() any {
+	return widget.Items
+}
+
+func (widget *FreshRSS) Render() template.HTML {
+	if widget.Style == "detailed-list" {
+		return widget.render(widget, assets.FreshRSSDetailedListTemplate)
+	}
+
+	return widget.render(widget, assets.FreshRSSListTemplate)
+}
@@ -0,0 +1,35 @@
+package widget
+
+import (
+	"testing"
+
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+func TestFreshRSSItemMatchesKeywords(t *testing.T) {
+	tests := []struct {
+		include      []string
+		exclude      []string
+		matchContent bool
+		item         feed.RSSFeedItem
+		expected     bool
+	}{
+		{nil, nil, false, feed.RSSFeedItem{Title: "Anything"}, true},
+		{[]string{"go"}, nil, false, feed.RSSFeedItem{Title: "Go 1.23 is released"}, true},
+		{[]string{"rust", "go"}, nil, false, feed.RSSFeedItem{Title: "Go 1.23 is released"}, true},
+		{[]string{"rust"}, nil, false, feed.RSSFeedItem{Title: "Go 1.23 is released"}, false},
+		{nil, []string{"sponsored"}, false, feed.RSSFeedItem{Title: "SPONSORED: a new laptop"}, false},
+		{[]string{"go"}, []string{"sponsored"}, false, feed.RSSFeedItem{Title: "Sponsored: learn Go"}, false},
+		{[]string{"generics"}, nil, false, feed.RSSFeedItem{Title: "Go 1.23", Description: "More on generics"}, false},
+		{[]string{"generics"}, nil, true, feed.RSSFeedItem{Title: "Go 1.23", Description: "More on Generics"}, true},
+		{nil, []string{"ad"}, true, feed.RSSFeedItem{Title: "Go 1.23", Description: "An ad for something"}, false},
+	}
+
+	for _, test := range tests {
+		widget := &FreshRSS{IncludeKeywords: test.include, ExcludeKeywords: test.exclude, MatchContent: test.matchContent}
+
+		if matches := widget.itemMatchesKeywords(&test.item); matches != test.expected {
+			t.Errorf("item %q with include %q and exclude %q: expected %t, got %t", test.item.Title, test.include, test.exclude, test.expected, matches)
+		}
+	}
+}
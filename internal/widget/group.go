@@ -26,7 +26,7 @@ func (widget *Group) Initialize() error {
 			return errors.New("nested groups are not allowed")
 		}
 
-		if err := widget.Widgets[i].Initialize(); err != nil {
+		if err := Initialize(widget.Widgets[i]); err != nil {
 			return err
 		}
 	}
@@ -48,7 +48,7 @@ func (widget *Group) Update(ctx context.Context) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			widget.Update(ctx)
+			UpdateWithDeadline(ctx, widget)
 		}()
 	}
 
@@ -61,6 +61,12 @@ func (widget *Group) SetProviders(providers *Providers) {
 	}
 }
 
+func (widget *Group) Invalidate() {
+	for i := range widget.Widgets {
+		widget.Widgets[i].Invalidate()
+	}
+}
+
 func (widget *Group) RequiresUpdate(now *time.Time) bool {
 	for i := range widget.Widgets {
 		if widget.Widgets[i].RequiresUpdate(now) {
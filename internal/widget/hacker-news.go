@@ -30,8 +30,8 @@ func (widget *HackerNews) Initialize() error {
 		widget.Limit = 15
 	}
 
-	if widget.CollapseAfter == 0 || widget.CollapseAfter < -1 {
-		widget.CollapseAfter = 5
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
 	}
 
 	if widget.SortBy != "top" && widget.SortBy != "new" && widget.SortBy != "best" {
@@ -42,7 +42,7 @@ func (widget *HackerNews) Initialize() error {
 }
 
 func (widget *HackerNews) Update(ctx context.Context) {
-	posts, err := feed.FetchHackerNewsPosts(widget.SortBy, 40, widget.CommentsUrlTemplate)
+	posts, err := feed.FetchHackerNewsPosts(widget.logger(), widget.SortBy, 40, widget.CommentsUrlTemplate)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -0,0 +1,75 @@
+package widget
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type JellyfinRecentlyAdded struct {
+	widgetBase    `yaml:",inline"`
+	URL           OptionalEnvString     `yaml:"url"`
+	APIKey        OptionalEnvString     `yaml:"api-key"`
+	UserID        OptionalEnvString     `yaml:"user-id"`
+	AllowInsecure bool                  `yaml:"allow-insecure"`
+	Limit         int                   `yaml:"limit"`
+	CollapseAfter int                   `yaml:"collapse-after"`
+	Items         feed.JellyfinItems    `yaml:"-"`
+	request       *feed.JellyfinRequest `yaml:"-"`
+}
+
+func (widget *JellyfinRecentlyAdded) Initialize() error {
+	if err := widget.URL.normalizeURL("jellyfin-recently-added widget"); err != nil {
+		return err
+	}
+
+	widget.withTitle("Recently Added").withTitleURL(string(widget.URL)).withCacheDuration(15 * time.Minute)
+
+	if widget.URL == "" {
+		return errors.New("url is required for the jellyfin-recently-added widget")
+	}
+
+	if widget.APIKey == "" {
+		return errors.New("api-key is required for the jellyfin-recently-added widget")
+	}
+
+	if widget.UserID == "" {
+		return errors.New("user-id is required for the jellyfin-recently-added widget")
+	}
+
+	if widget.Limit <= 0 {
+		widget.Limit = 10
+	}
+
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
+	}
+
+	widget.request = &feed.JellyfinRequest{
+		URL:           string(widget.URL),
+		APIKey:        string(widget.APIKey),
+		UserID:        string(widget.UserID),
+		AllowInsecure: widget.AllowInsecure,
+		Limit:         widget.Limit,
+	}
+
+	return nil
+}
+
+func (widget *JellyfinRecentlyAdded) Update(ctx context.Context) {
+	items, err := feed.FetchJellyfinRecentlyAdded(ctx, widget.request)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.Items = items
+}
+
+func (widget *JellyfinRecentlyAdded) Render() template.HTML {
+	return widget.render(widget, assets.JellyfinRecentlyAddedTemplate)
+}
@@ -0,0 +1,83 @@
+package widget
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type JSONAPI struct {
+	widgetBase    `yaml:",inline"`
+	URL           OptionalEnvString    `yaml:"url"`
+	Headers       HeadersField         `yaml:"headers"`
+	AllowInsecure bool                 `yaml:"allow-insecure"`
+	Timeout       DurationField        `yaml:"timeout"`
+	ItemsPath     string               `yaml:"items"`
+	Fields        map[string]string    `yaml:"fields"`
+	Template      string               `yaml:"template"`
+	Content       template.HTML        `yaml:"-"`
+	request       *feed.JSONAPIRequest `yaml:"-"`
+	compiled      *template.Template   `yaml:"-"`
+}
+
+func (widget *JSONAPI) Initialize() error {
+	if err := widget.URL.normalizeURL("json-api widget"); err != nil {
+		return err
+	}
+
+	widget.withTitle("API").withCacheDuration(10 * time.Minute)
+
+	if widget.URL == "" {
+		return errors.New("url is required for the json-api widget")
+	}
+
+	if widget.Template == "" {
+		return errors.New("template is required for the json-api widget")
+	}
+
+	compiled, err := assets.CompileUserTemplate(widget.Template)
+
+	if err != nil {
+		return fmt.Errorf("parsing template of json-api widget: %v", err)
+	}
+
+	widget.compiled = compiled
+
+	widget.request = &feed.JSONAPIRequest{
+		URL:           string(widget.URL),
+		Headers:       widget.Headers.toMap(),
+		AllowInsecure: widget.AllowInsecure,
+		Timeout:       time.Duration(widget.Timeout),
+		ItemsPath:     widget.ItemsPath,
+		Fields:        widget.Fields,
+	}
+
+	return nil
+}
+
+func (widget *JSONAPI) Update(ctx context.Context) {
+	response, err := feed.FetchJSONAPI(ctx, widget.request)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	var buffer bytes.Buffer
+
+	if err := widget.compiled.Execute(&buffer, response); err != nil {
+		widget.withError(fmt.Errorf("executing template: %v", err))
+		return
+	}
+
+	widget.Content = template.HTML(buffer.String())
+}
+
+func (widget *JSONAPI) Render() template.HTML {
+	return widget.render(widget, assets.JSONAPITemplate)
+}
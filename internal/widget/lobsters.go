@@ -38,8 +38,8 @@ func (widget *Lobsters) Initialize() error {
 		widget.Limit = 15
 	}
 
-	if widget.CollapseAfter == 0 || widget.CollapseAfter < -1 {
-		widget.CollapseAfter = 5
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
 	}
 
 	return nil
@@ -28,7 +28,7 @@ func (widget *Markets) Initialize() error {
 }
 
 func (widget *Markets) Update(ctx context.Context) {
-	markets, err := feed.FetchMarketsDataFromYahoo(widget.MarketRequests)
+	markets, err := feed.FetchMarketsDataFromYahoo(widget.logger(), widget.MarketRequests)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
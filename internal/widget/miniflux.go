@@ -0,0 +1,107 @@
+package widget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type Miniflux struct {
+	widgetBase       `yaml:",inline"`
+	URL              OptionalEnvString     `yaml:"url"`
+	APIToken         OptionalEnvString     `yaml:"api-token"`
+	AllowInsecure    bool                  `yaml:"allow-insecure"`
+	CACertPath       string                `yaml:"ca-cert-path"`
+	Headers          HeadersField          `yaml:"headers"`
+	Style            string                `yaml:"style"`
+	Status           string                `yaml:"status"`
+	Limit            int                   `yaml:"limit"`
+	MaxAge           DurationField         `yaml:"max-age"`
+	CollapseAfter    int                   `yaml:"collapse-after"`
+	SingleLineTitles bool                  `yaml:"single-line-titles"`
+	Items            feed.RSSFeedItems     `yaml:"-"`
+	NoItemsMessage   string                `yaml:"-"`
+	request          *feed.MinifluxRequest `yaml:"-"`
+
+	// entries are fetched in a single request, these only exist
+	// because the templates are shared with the freshrss widget
+	ShowFailedFeeds bool `yaml:"-"`
+	FailedFeeds     int  `yaml:"-"`
+}
+
+func (widget *Miniflux) Initialize() error {
+	if err := widget.URL.normalizeURL("miniflux widget"); err != nil {
+		return err
+	}
+
+	widget.withTitle("Miniflux").withTitleURL(string(widget.URL)).withCacheDuration(30 * time.Minute)
+
+	if widget.URL == "" {
+		return errors.New("url is required for the miniflux widget")
+	}
+
+	if widget.APIToken == "" {
+		return errors.New("api-token is required for the miniflux widget")
+	}
+
+	if widget.Status == "" {
+		widget.Status = "unread"
+	}
+
+	if widget.Status != "unread" && widget.Status != "read" && widget.Status != "all" {
+		return fmt.Errorf("invalid status '%s' for miniflux widget, must be one of unread, read or all", widget.Status)
+	}
+
+	if widget.Limit <= 0 {
+		widget.Limit = 25
+	}
+
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
+	}
+
+	widget.request = &feed.MinifluxRequest{
+		URL:           string(widget.URL),
+		APIToken:      string(widget.APIToken),
+		AllowInsecure: widget.AllowInsecure,
+		CACertPath:    widget.CACertPath,
+		Headers:       widget.Headers.toMap(),
+		IsDetailed:    widget.Style == "detailed-list",
+		MaxAge:        time.Duration(widget.MaxAge),
+	}
+
+	if widget.Status != "all" {
+		widget.request.Status = widget.Status
+	}
+
+	widget.NoItemsMessage = "No items were returned from Miniflux."
+
+	return nil
+}
+
+func (widget *Miniflux) Update(ctx context.Context) {
+	items, err := feed.GetItemsFromMiniflux(ctx, widget.logger(), widget.request, widget.Limit)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.Items = items
+}
+
+func (widget *Miniflux) Data() any {
+	return widget.Items
+}
+
+func (widget *Miniflux) Render() template.HTML {
+	if widget.Style == "detailed-list" {
+		return widget.render(widget, assets.FreshRSSDetailedListTemplate)
+	}
+
+	return widget.render(widget, assets.FreshRSSListTemplate)
+}
@@ -0,0 +1,96 @@
+package widget
+
+import (
+	"context"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type RadarrCollections struct {
+	widgetBase          `yaml:",inline"`
+	arrConnectionConfig `yaml:",inline"`
+	Name                string                       `yaml:"name"`
+	Limit               int                          `yaml:"limit"`
+	CollapseAfter       int                          `yaml:"collapse-after"`
+	PosterURLTemplate   string                       `yaml:"poster-url-template"`
+	ImageType           string                       `yaml:"image-type"`
+	ThumbnailSize       int                          `yaml:"thumbnail-size"`
+	PosterWidth         int                          `yaml:"poster-width"`
+	PosterSize          arrPosterSize                `yaml:"-"`
+	Movies              []feed.RadarrCollectionMovie `yaml:"-"`
+	request             *feed.ArrReleaseRequest      `yaml:"-"`
+}
+
+func (widget *RadarrCollections) Initialize() error {
+	if err := widget.normalizeURLs("the radarr-collections widget"); err != nil {
+		return err
+	}
+
+	widget.withArrName(widget.Name, "Coming Soon in Collections").withTitleURL(widget.linkURL())
+
+	request, err := widget.newRequestFor(&widget.widgetBase, feed.ArrSourceRadarr, 6*time.Hour, "the radarr-collections widget")
+
+	if request == nil {
+		return err
+	}
+
+	if err := validatePosterURLTemplate(widget.PosterURLTemplate, "radarr-collections widget"); err != nil {
+		return err
+	}
+
+	if err := validateImageType(&widget.ImageType, "radarr-collections widget"); err != nil {
+		return err
+	}
+
+	if err := validateThumbnailSize(widget.ThumbnailSize, "radarr-collections widget"); err != nil {
+		return err
+	}
+
+	size, err := newArrPosterSize(widget.ImageType, widget.PosterWidth, "radarr-collections widget")
+
+	if err != nil {
+		return err
+	}
+
+	widget.PosterSize = size
+
+	if widget.Limit <= 0 {
+		widget.Limit = 10
+	}
+
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
+	}
+
+	widget.request = request
+	widget.request.PosterURLTemplate = widget.PosterURLTemplate
+	widget.request.ImageType = widget.ImageType
+	widget.request.ThumbnailSize = widget.ThumbnailSize
+
+	return nil
+}
+
+func (widget *RadarrCollections) Update(ctx context.Context) {
+	movies, err := feed.FetchRadarrCollectionMovies(ctx, widget.request, widget.Limit, time.Now())
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.Movies = movies
+}
+
+func (widget *RadarrCollections) TestConnections() []ConnectionTestResult {
+	if widget.request == nil {
+		return nil
+	}
+
+	return []ConnectionTestResult{testArrConnection(widget.GetType(), widget.request)}
+}
+
+func (widget *RadarrCollections) Render() template.HTML {
+	return widget.render(widget, assets.RadarrCollectionsTemplate)
+}
@@ -37,8 +37,8 @@ func (widget *Reddit) Initialize() error {
 		widget.Limit = 15
 	}
 
-	if widget.CollapseAfter == 0 || widget.CollapseAfter < -1 {
-		widget.CollapseAfter = 5
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
 	}
 
 	if !isValidRedditSortType(widget.SortBy) {
@@ -30,8 +30,8 @@ func (widget *Releases) Initialize() error {
 		widget.Limit = 10
 	}
 
-	if widget.CollapseAfter == 0 || widget.CollapseAfter < -1 {
-		widget.CollapseAfter = 5
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
 	}
 
 	var tokenAsString = widget.Token.String()
@@ -81,7 +81,7 @@ func (widget *Releases) Initialize() error {
 }
 
 func (widget *Releases) Update(ctx context.Context) {
-	releases, err := feed.FetchLatestReleases(widget.releaseRequests)
+	releases, err := feed.FetchLatestReleases(widget.logger(), widget.releaseRequests)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
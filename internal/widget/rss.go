@@ -2,6 +2,7 @@ package widget
 
 import (
 	"context"
+	"fmt"
 	"html/template"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 type RSS struct {
 	widgetBase       `yaml:",inline"`
 	FeedRequests     []feed.RSSFeedRequest `yaml:"feeds"`
+	OPMLPath         string                `yaml:"opml-path"`
 	Style            string                `yaml:"style"`
 	ThumbnailHeight  float64               `yaml:"thumbnail-height"`
 	CardHeight       float64               `yaml:"card-height"`
@@ -29,8 +31,8 @@ func (widget *RSS) Initialize() error {
 		widget.Limit = 25
 	}
 
-	if widget.CollapseAfter == 0 || widget.CollapseAfter < -1 {
-		widget.CollapseAfter = 5
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
 	}
 
 	if widget.ThumbnailHeight < 0 {
@@ -41,6 +43,16 @@ func (widget *RSS) Initialize() error {
 		widget.CardHeight = 0
 	}
 
+	if widget.OPMLPath != "" {
+		requests, err := feed.ParseFeedRequestsFromOPMLFile(widget.OPMLPath)
+
+		if err != nil {
+			return fmt.Errorf("loading feeds from OPML file %s: %v", widget.OPMLPath, err)
+		}
+
+		widget.FeedRequests = append(widget.FeedRequests, requests...)
+	}
+
 	if widget.Style == "detailed-list" {
 		for i := range widget.FeedRequests {
 			widget.FeedRequests[i].IsDetailed = true
@@ -53,7 +65,7 @@ func (widget *RSS) Initialize() error {
 }
 
 func (widget *RSS) Update(ctx context.Context) {
-	items, err := feed.GetItemsFromRSSFeeds(widget.FeedRequests)
+	items, err := feed.GetItemsFromRSSFeeds(widget.logger(), widget.FeedRequests)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -66,6 +78,10 @@ func (widget *RSS) Update(ctx context.Context) {
 	widget.Items = items
 }
 
+func (widget *RSS) Data() any {
+	return widget.Items
+}
+
 func (widget *RSS) Render() template.HTML {
 	if widget.Style == "horizontal-cards" {
 		return widget.render(widget, assets.RSSHorizontalCardsTemplate)
@@ -0,0 +1,106 @@
+package widget
+
+import (
+	"context"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type SonarrCutoffUnmet struct {
+	widgetBase          `yaml:",inline"`
+	arrConnectionConfig `yaml:",inline"`
+	Name                string                  `yaml:"name"`
+	Limit               int                     `yaml:"limit"`
+	CollapseAfter       int                     `yaml:"collapse-after"`
+	PosterURLTemplate   string                  `yaml:"poster-url-template"`
+	ImageType           string                  `yaml:"image-type"`
+	Locale              string                  `yaml:"locale"`
+	DateFormat          string                  `yaml:"-"`
+	ThumbnailSize       int                     `yaml:"thumbnail-size"`
+	PosterWidth         int                     `yaml:"poster-width"`
+	PosterSize          arrPosterSize           `yaml:"-"`
+	CutoffUnmet         *feed.SonarrCutoffUnmet `yaml:"-"`
+	request             *feed.ArrReleaseRequest `yaml:"-"`
+}
+
+func (widget *SonarrCutoffUnmet) Initialize() error {
+	if err := widget.normalizeURLs("the sonarr-cutoff-unmet widget"); err != nil {
+		return err
+	}
+
+	widget.withArrName(widget.Name, "Cutoff Unmet").withTitleURL(widget.linkURL())
+
+	request, err := widget.newRequestFor(&widget.widgetBase, feed.ArrSourceSonarr, time.Hour, "the sonarr-cutoff-unmet widget")
+
+	if request == nil {
+		return err
+	}
+
+	if err := validatePosterURLTemplate(widget.PosterURLTemplate, "sonarr-cutoff-unmet widget"); err != nil {
+		return err
+	}
+
+	if err := validateImageType(&widget.ImageType, "sonarr-cutoff-unmet widget"); err != nil {
+		return err
+	}
+
+	dateLayouts, err := getLocaleDateLayouts(widget.Locale, "sonarr-cutoff-unmet widget")
+
+	if err != nil {
+		return err
+	}
+
+	widget.DateFormat = dateLayouts.full
+
+	if err := validateThumbnailSize(widget.ThumbnailSize, "sonarr-cutoff-unmet widget"); err != nil {
+		return err
+	}
+
+	size, err := newArrPosterSize(widget.ImageType, widget.PosterWidth, "sonarr-cutoff-unmet widget")
+
+	if err != nil {
+		return err
+	}
+
+	widget.PosterSize = size
+
+	if widget.Limit <= 0 {
+		widget.Limit = 10
+	}
+
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
+	}
+
+	widget.request = request
+	widget.request.PosterURLTemplate = widget.PosterURLTemplate
+	widget.request.ImageType = widget.ImageType
+	widget.request.ThumbnailSize = widget.ThumbnailSize
+
+	return nil
+}
+
+func (widget *SonarrCutoffUnmet) Update(ctx context.Context) {
+	cutoffUnmet, err := feed.FetchSonarrCutoffUnmet(ctx, widget.request, widget.Limit)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.CutoffUnmet = cutoffUnmet
+}
+
+func (widget *SonarrCutoffUnmet) TestConnections() []ConnectionTestResult {
+	if widget.request == nil {
+		return nil
+	}
+
+	return []ConnectionTestResult{testArrConnection(widget.GetType(), widget.request)}
+}
+
+func (widget *SonarrCutoffUnmet) Render() template.HTML {
+	return widget.render(widget, assets.SonarrCutoffUnmetTemplate)
+}
@@ -0,0 +1,149 @@
+package widget
+
+import (
+	"context"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type SonarrPremieres struct {
+	widgetBase          `yaml:",inline"`
+	arrConnectionConfig `yaml:",inline"`
+	Name                string                  `yaml:"name"`
+	Networks            []string                `yaml:"networks"`
+	Days                int                     `yaml:"days"`
+	OverviewLength      int                     `yaml:"overview-length"`
+	CollapseAfter       int                     `yaml:"collapse-after"`
+	PosterURLTemplate   string                  `yaml:"poster-url-template"`
+	ImageType           string                  `yaml:"image-type"`
+	ThumbnailSize       int                     `yaml:"thumbnail-size"`
+	PosterWidth         int                     `yaml:"poster-width"`
+	IncludeTitleRegex   string                  `yaml:"include-title-regex"`
+	ExcludeTitleRegex   string                  `yaml:"exclude-title-regex"`
+	PosterSize          arrPosterSize           `yaml:"-"`
+	titleFilter         arrTitleFilter          `yaml:"-"`
+	AirDateSource       string                  `yaml:"air-date-source"`
+	IncludeWeekdays     []string                `yaml:"include-weekdays"`
+	ExtraQuery          map[string]string       `yaml:"extra-query"`
+	Locale              string                  `yaml:"locale"`
+	DateFormat          string                  `yaml:"-"`
+	Premieres           feed.ArrReleases        `yaml:"-"`
+	request             *feed.ArrReleaseRequest `yaml:"-"`
+}
+
+func (widget *SonarrPremieres) Initialize() error {
+	if err := widget.normalizeURLs("the sonarr-premieres widget"); err != nil {
+		return err
+	}
+
+	widget.withArrName(widget.Name, "Upcoming Premieres").withTitleURL(widget.linkURL())
+
+	request, err := widget.newRequestFor(&widget.widgetBase, feed.ArrSourceSonarr, time.Hour, "the sonarr-premieres widget")
+
+	if request == nil {
+		return err
+	}
+
+	if err := validatePosterURLTemplate(widget.PosterURLTemplate, "sonarr-premieres widget"); err != nil {
+		return err
+	}
+
+	if err := validateImageType(&widget.ImageType, "sonarr-premieres widget"); err != nil {
+		return err
+	}
+
+	dateLayouts, err := getLocaleDateLayouts(widget.Locale, "sonarr-premieres widget")
+
+	if err != nil {
+		return err
+	}
+
+	widget.DateFormat = dateLayouts.short
+
+	if err := validateThumbnailSize(widget.ThumbnailSize, "sonarr-premieres widget"); err != nil {
+		return err
+	}
+
+	size, err := newArrPosterSize(widget.ImageType, widget.PosterWidth, "sonarr-premieres widget")
+
+	if err != nil {
+		return err
+	}
+
+	widget.PosterSize = size
+
+	titleFilter, err := newArrTitleFilter(widget.IncludeTitleRegex, widget.ExcludeTitleRegex, "sonarr-premieres widget")
+
+	if err != nil {
+		return err
+	}
+
+	widget.titleFilter = titleFilter
+
+	if err := validateAirDateSource(widget.AirDateSource, "sonarr-premieres widget"); err != nil {
+		return err
+	}
+
+	if err := validateExtraQuery(widget.ExtraQuery, "sonarr-premieres widget"); err != nil {
+		return err
+	}
+
+	weekdays, err := parseWeekdays(widget.IncludeWeekdays, "sonarr-premieres widget")
+
+	if err != nil {
+		return err
+	}
+
+	if widget.Days <= 0 {
+		widget.Days = 30
+	}
+
+	if widget.OverviewLength == 0 {
+		widget.OverviewLength = 140
+	}
+
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
+	}
+
+	widget.request = request
+	widget.request.Networks = widget.Networks
+	widget.request.OverviewLength = widget.OverviewLength
+	widget.request.PosterURLTemplate = widget.PosterURLTemplate
+	widget.request.ImageType = widget.ImageType
+	widget.request.ThumbnailSize = widget.ThumbnailSize
+	widget.request.AirDateSource = widget.AirDateSource
+	widget.request.Weekdays = weekdays
+	widget.request.ExtraQuery = widget.ExtraQuery
+
+	return nil
+}
+
+func (widget *SonarrPremieres) Update(ctx context.Context) {
+	premieres, err := feed.FetchSonarrPremieres(ctx, widget.request, time.Now(), widget.Days)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.Premieres = widget.titleFilter.apply(premieres)
+}
+
+func (widget *SonarrPremieres) Data() any {
+	return widget.Premieres
+}
+
+func (widget *SonarrPremieres) TestConnections() []ConnectionTestResult {
+	if widget.request == nil {
+		return nil
+	}
+
+	return []ConnectionTestResult{testArrConnection(widget.GetType(), widget.request)}
+}
+
+func (widget *SonarrPremieres) Render() template.HTML {
+	return widget.render(widget, assets.SonarrPremieresTemplate)
+}
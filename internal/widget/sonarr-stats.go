@@ -0,0 +1,58 @@
+package widget
+
+import (
+	"context"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type SonarrStats struct {
+	widgetBase          `yaml:",inline"`
+	arrConnectionConfig `yaml:",inline"`
+	Name                string                  `yaml:"name"`
+	Stats               *feed.SonarrStats       `yaml:"-"`
+	request             *feed.ArrReleaseRequest `yaml:"-"`
+}
+
+func (widget *SonarrStats) Initialize() error {
+	if err := widget.normalizeURLs("the sonarr-stats widget"); err != nil {
+		return err
+	}
+
+	widget.withArrName(widget.Name, "Sonarr").withTitleURL(widget.linkURL())
+
+	request, err := widget.newRequestFor(&widget.widgetBase, feed.ArrSourceSonarr, time.Hour, "the sonarr-stats widget")
+
+	if request == nil {
+		return err
+	}
+
+	widget.request = request
+
+	return nil
+}
+
+func (widget *SonarrStats) Update(ctx context.Context) {
+	stats, err := feed.FetchSonarrStats(ctx, widget.request)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.Stats = stats
+}
+
+func (widget *SonarrStats) TestConnections() []ConnectionTestResult {
+	if widget.request == nil {
+		return nil
+	}
+
+	return []ConnectionTestResult{testArrConnection(widget.GetType(), widget.request)}
+}
+
+func (widget *SonarrStats) Render() template.HTML {
+	return widget.render(widget, assets.SonarrStatsTemplate)
+}
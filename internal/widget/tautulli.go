@@ -0,0 +1,64 @@
+package widget
+
+import (
+	"context"
+	"errors"
+	"html/template"
+	"time"
+
+	"github.com/glanceapp/glance/internal/assets"
+	"github.com/glanceapp/glance/internal/feed"
+)
+
+type Tautulli struct {
+	widgetBase    `yaml:",inline"`
+	URL           OptionalEnvString      `yaml:"url"`
+	APIKey        OptionalEnvString      `yaml:"api-key"`
+	AllowInsecure bool                   `yaml:"allow-insecure"`
+	HistoryLimit  int                    `yaml:"history-limit"`
+	Activity      *feed.TautulliActivity `yaml:"-"`
+	request       *feed.TautulliRequest  `yaml:"-"`
+}
+
+func (widget *Tautulli) Initialize() error {
+	if err := widget.URL.normalizeURL("tautulli widget"); err != nil {
+		return err
+	}
+
+	widget.withTitle("Now Playing").withTitleURL(string(widget.URL)).withCacheDuration(time.Minute)
+
+	if widget.URL == "" {
+		return errors.New("url is required for the tautulli widget")
+	}
+
+	if widget.APIKey == "" {
+		return errors.New("api-key is required for the tautulli widget")
+	}
+
+	if widget.HistoryLimit == 0 {
+		widget.HistoryLimit = 5
+	}
+
+	widget.request = &feed.TautulliRequest{
+		URL:           string(widget.URL),
+		APIKey:        string(widget.APIKey),
+		AllowInsecure: widget.AllowInsecure,
+		HistoryLimit:  widget.HistoryLimit,
+	}
+
+	return nil
+}
+
+func (widget *Tautulli) Update(ctx context.Context) {
+	activity, err := feed.FetchTautulliActivity(ctx, widget.request)
+
+	if !widget.canContinueUpdateAfterHandlingErr(err) {
+		return
+	}
+
+	widget.Activity = activity
+}
+
+func (widget *Tautulli) Render() template.HTML {
+	return widget.render(widget, assets.TautulliTemplate)
+}
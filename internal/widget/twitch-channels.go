@@ -23,8 +23,8 @@ func (widget *TwitchChannels) Initialize() error {
 		withTitleURL("https://www.twitch.tv/directory/following").
 		withCacheDuration(time.Minute * 10)
 
-	if widget.CollapseAfter == 0 || widget.CollapseAfter < -1 {
-		widget.CollapseAfter = 5
+	if widget.CollapseAfter < -1 {
+		widget.CollapseAfter = defaultCollapseAfter
 	}
 
 	if widget.SortBy != "viewers" && widget.SortBy != "live" {
@@ -35,7 +35,7 @@ func (widget *TwitchChannels) Initialize() error {
 }
 
 func (widget *TwitchChannels) Update(ctx context.Context) {
-	channels, err := feed.FetchChannelsFromTwitch(widget.ChannelsRequest)
+	channels, err := feed.FetchChannelsFromTwitch(widget.logger(), widget.ChannelsRequest)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -27,15 +27,15 @@ func (widget *Videos) Initialize() error {
 		widget.Limit = 25
 	}
 
-	if widget.CollapseAfterRows == 0 || widget.CollapseAfterRows < -1 {
-		widget.CollapseAfterRows = 4
+	if widget.CollapseAfterRows < -1 {
+		widget.CollapseAfterRows = defaultCollapseAfterRows
 	}
 
 	return nil
 }
 
 func (widget *Videos) Update(ctx context.Context) {
-	videos, err := feed.FetchYoutubeChannelUploads(widget.Channels, widget.VideoUrlTemplate, widget.IncludeShorts)
+	videos, err := feed.FetchYoutubeChannelUploads(widget.logger(), widget.Channels, widget.VideoUrlTemplate, widget.IncludeShorts)
 
 	if !widget.canContinueUpdateAfterHandlingErr(err) {
 		return
@@ -8,10 +8,12 @@ import (
 	"html/template"
 	"log/slog"
 	"math"
+	"math/rand/v2"
 	"net/http"
 	"sync/atomic"
 	"time"
 
+	"github.com/glanceapp/glance/internal/assets"
 	"github.com/glanceapp/glance/internal/feed"
 
 	"gopkg.in/yaml.v3"
@@ -19,6 +21,13 @@ import (
 
 var uniqueID atomic.Uint64
 
+// set before the config gets decoded rather than in Initialize so that
+// a value of 0 (collapsed by default) can be told apart from no value
+const (
+	defaultCollapseAfter     = 5
+	defaultCollapseAfterRows = 4
+)
+
 func New(widgetType string) (Widget, error) {
 	var widget Widget
 
@@ -36,27 +45,33 @@ func New(widgetType string) (Widget, error) {
 	case "html":
 		widget = &HTML{}
 	case "hacker-news":
-		widget = &HackerNews{}
+		widget = &HackerNews{CollapseAfter: defaultCollapseAfter}
 	case "releases":
-		widget = &Releases{}
+		widget = &Releases{CollapseAfter: defaultCollapseAfter}
 	case "videos":
-		widget = &Videos{}
+		widget = &Videos{CollapseAfterRows: defaultCollapseAfterRows}
 	case "markets", "stocks":
 		widget = &Markets{}
 	case "reddit":
-		widget = &Reddit{}
+		widget = &Reddit{CollapseAfter: defaultCollapseAfter}
 	case "rss":
-		widget = &RSS{}
+		widget = &RSS{CollapseAfter: defaultCollapseAfter}
+	case "freshrss":
+		widget = &FreshRSS{CollapseAfter: defaultCollapseAfter}
+	case "freshrss-unread":
+		widget = &FreshRSSUnread{}
+	case "miniflux":
+		widget = &Miniflux{CollapseAfter: defaultCollapseAfter}
 	case "monitor":
 		widget = &Monitor{}
 	case "twitch-top-games":
-		widget = &TwitchGames{}
+		widget = &TwitchGames{CollapseAfter: defaultCollapseAfter}
 	case "twitch-channels":
-		widget = &TwitchChannels{}
+		widget = &TwitchChannels{CollapseAfter: defaultCollapseAfter}
 	case "lobsters":
-		widget = &Lobsters{}
+		widget = &Lobsters{CollapseAfter: defaultCollapseAfter}
 	case "change-detection":
-		widget = &ChangeDetection{}
+		widget = &ChangeDetection{CollapseAfter: defaultCollapseAfter}
 	case "repository":
 		widget = &Repository{}
 	case "search":
@@ -67,6 +82,24 @@ func New(widgetType string) (Widget, error) {
 		widget = &Group{}
 	case "dns-stats":
 		widget = &DNSStats{}
+	case "arr-releases":
+		widget = &ArrReleases{CollapseAfter: defaultCollapseAfter}
+	case "sonarr-premieres":
+		widget = &SonarrPremieres{CollapseAfter: defaultCollapseAfter}
+	case "sonarr-stats":
+		widget = &SonarrStats{}
+	case "sonarr-cutoff-unmet":
+		widget = &SonarrCutoffUnmet{CollapseAfter: defaultCollapseAfter}
+	case "radarr-collections":
+		widget = &RadarrCollections{CollapseAfter: defaultCollapseAfter}
+	case "jellyfin-recently-added":
+		widget = &JellyfinRecentlyAdded{CollapseAfter: defaultCollapseAfter}
+	case "tautulli":
+		widget = &Tautulli{}
+	case "download-client":
+		widget = &DownloadClient{CollapseAfter: defaultCollapseAfter}
+	case "json-api":
+		widget = &JSONAPI{}
 	default:
 		return nil, fmt.Errorf("unknown widget type: %s", widgetType)
 	}
@@ -110,6 +143,29 @@ func (w *Widgets) UnmarshalYAML(node *yaml.Node) error {
 	return nil
 }
 
+// implemented by widgets whose fetched data can be requested as JSON through the API
+type DataProvider interface {
+	Data() any
+}
+
+type ConnectionTestResult struct {
+	Name    string
+	Details string
+	Err     error
+}
+
+// implemented by widgets with endpoints that serve the data they fetched, so that the
+// widget can be brought up to date first when a request to one of them needs it
+type UpdatingRequestHandler interface {
+	RequestRequiresUpdate(r *http.Request) bool
+}
+
+// implemented by widgets that connect to self-hosted services so that their
+// configuration can be checked from the command line
+type ConnectionTester interface {
+	TestConnections() []ConnectionTestResult
+}
+
 type Widget interface {
 	Initialize() error
 	RequiresUpdate(*time.Time) bool
@@ -118,6 +174,9 @@ type Widget interface {
 	Render() template.HTML
 	GetType() string
 	GetID() uint64
+	GetError() error
+	GetUpdateDeadline() time.Duration
+	Invalidate()
 	SetID(uint64)
 	HandleRequest(w http.ResponseWriter, r *http.Request)
 	SetHideHeader(bool)
@@ -139,6 +198,9 @@ type widgetBase struct {
 	TitleURL            string        `yaml:"title-url"`
 	CSSClass            string        `yaml:"css-class"`
 	CustomCacheDuration DurationField `yaml:"cache"`
+	ErrorCacheDuration  DurationField `yaml:"error-cache"`
+	UpdateDeadline      DurationField `yaml:"update-deadline"`
+	TemplateFile        string        `yaml:"template-file"`
 	ContentAvailable    bool          `yaml:"-"`
 	Error               error         `yaml:"-"`
 	Notice              error         `yaml:"-"`
@@ -148,10 +210,15 @@ type widgetBase struct {
 	nextUpdate          time.Time     `yaml:"-"`
 	updateRetriedTimes  int           `yaml:"-"`
 	HideHeader          bool          `yaml:"-"`
+	// included in the logs of widgets that can be given a name
+	name string `yaml:"-"`
+	// parsed from the template-file, used instead of the widget's own template when set
+	customTemplate *template.Template `yaml:"-"`
 }
 
 type Providers struct {
 	AssetResolver func(string) string
+	APIResolver   func(string) string
 }
 
 func (w *widgetBase) RequiresUpdate(now *time.Time) bool {
@@ -170,6 +237,60 @@ func (w *widgetBase) Update(ctx context.Context) {
 
 }
 
+func (w *widgetBase) GetUpdateDeadline() time.Duration {
+	return time.Duration(w.UpdateDeadline)
+}
+
+// the template-file is parsed before the widget is initialized since
+// some widgets render themselves only once, while being initialized
+func Initialize(w Widget) error {
+	if base, ok := w.(interface{ initializeTemplateFile() error }); ok {
+		if err := base.initializeTemplateFile(); err != nil {
+			return err
+		}
+	}
+
+	return w.Initialize()
+}
+
+func (w *widgetBase) initializeTemplateFile() error {
+	if w.TemplateFile == "" {
+		return nil
+	}
+
+	t, err := assets.CompileUserTemplateFile(w.TemplateFile)
+
+	if err != nil {
+		return fmt.Errorf("loading template-file for %s widget: %v", w.Type, err)
+	}
+
+	w.customTemplate = t
+
+	return nil
+}
+
+// cancels everything the update is doing once the widget's update-deadline is
+// exceeded, which covers all of the requests it makes rather than each on its own
+func UpdateWithDeadline(ctx context.Context, w Widget) {
+	if deadline := w.GetUpdateDeadline(); deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, deadline)
+		defer cancel()
+	}
+
+	w.Update(ctx)
+}
+
+// makes the widget get updated the next time it's loaded regardless of its cache
+func (w *widgetBase) Invalidate() {
+	w.nextUpdate = time.Time{}
+}
+
+// widgets that never get updated have nothing to refresh
+func (w *widgetBase) IsRefreshable() bool {
+	return w.cacheType != cacheTypeInfinite
+}
+
 func (w *widgetBase) GetID() uint64 {
 	return w.ID
 }
@@ -190,11 +311,41 @@ func (w *widgetBase) GetType() string {
 	return w.Type
 }
 
+func (w *widgetBase) GetError() error {
+	return w.Error
+}
+
+// unlike Error, also includes failures that only some of the content couldn't be
+// loaded because of, so that templates can tell them apart from there being no content
+func (w *widgetBase) LastUpdateError() error {
+	if w.Error != nil {
+		return w.Error
+	}
+
+	return w.Notice
+}
+
 func (w *widgetBase) SetProviders(providers *Providers) {
 	w.Providers = providers
 }
 
+// attached to log lines about the widget, including the ones made while fetching its
+// data, so that they can be told apart when several widgets of the same type are configured
+func (w *widgetBase) logger() *slog.Logger {
+	logger := slog.With("widget_id", w.ID, "widget_type", w.Type, "widget_title", w.Title)
+
+	if w.name != "" {
+		logger = logger.With("widget_name", w.name)
+	}
+
+	return logger
+}
+
 func (w *widgetBase) render(data any, t *template.Template) template.HTML {
+	if w.customTemplate != nil {
+		t = w.customTemplate
+	}
+
 	w.templateBuffer.Reset()
 	err := t.Execute(&w.templateBuffer, data)
 
@@ -202,7 +353,7 @@ func (w *widgetBase) render(data any, t *template.Template) template.HTML {
 		w.ContentAvailable = false
 		w.Error = err
 
-		slog.Error("failed to render template", "error", err)
+		w.logger().Error("failed to render template", "error", err)
 
 		// need to immediately re-render with the error,
 		// otherwise risk breaking the page since the widget
@@ -211,7 +362,7 @@ func (w *widgetBase) render(data any, t *template.Template) template.HTML {
 		err2 := t.Execute(&w.templateBuffer, data)
 
 		if err2 != nil {
-			slog.Error("failed to render error within widget", "error", err2, "initial_error", err)
+			w.logger().Error("failed to render error within widget", "error", err2, "initial_error", err)
 			w.templateBuffer.Reset()
 			// TODO: add some kind of a generic widget error template when the widget
 			// failed to render, and we also failed to re-render the widget with the error
@@ -309,7 +460,10 @@ func (w *widgetBase) getNextUpdateTime() time.Time {
 	now := time.Now()
 
 	if w.cacheType == cacheTypeDuration {
-		return now.Add(w.cacheDuration)
+		// spread out the updates of widgets with the same cache duration by up to ±10%
+		jitter := time.Duration((rand.Float64()*0.2 - 0.1) * float64(w.cacheDuration))
+
+		return now.Add(w.cacheDuration + jitter)
 	}
 
 	if w.cacheType == cacheTypeOnTheHour {
@@ -344,5 +498,13 @@ func (w *widgetBase) scheduleEarlyUpdate() *widgetBase {
 		w.nextUpdate = nextEarlyUpdate
 	}
 
+	// the first retry already waits a minute, error-cache only has an effect when it's
+	// longer than that or than the usual cache duration
+	if w.ErrorCacheDuration > 0 {
+		if earliestUpdate := time.Now().Add(time.Duration(w.ErrorCacheDuration)); w.nextUpdate.Before(earliestUpdate) {
+			w.nextUpdate = earliestUpdate
+		}
+	}
+
 	return w
 }
@@ -0,0 +1,46 @@
+package widget
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleEarlyUpdateRespectsErrorCache(t *testing.T) {
+	tests := []struct {
+		cacheDuration time.Duration
+		errorCache    time.Duration
+		retries       int
+		expected      time.Duration
+	}{
+		{time.Hour, 0, 0, time.Minute},
+		{time.Hour, 0, 2, 9 * time.Minute},
+		{time.Hour, 10 * time.Minute, 0, 10 * time.Minute},
+		{time.Hour, 10 * time.Minute, 3, 16 * time.Minute},
+		{30 * time.Second, 0, 0, 30 * time.Second},
+		{30 * time.Second, 5 * time.Minute, 0, 5 * time.Minute},
+	}
+
+	for _, test := range tests {
+		widget := &widgetBase{
+			cacheType:          cacheTypeDuration,
+			cacheDuration:      test.cacheDuration,
+			ErrorCacheDuration: DurationField(test.errorCache),
+			updateRetriedTimes: test.retries,
+		}
+
+		before := time.Now()
+		widget.scheduleEarlyUpdate()
+		wait := widget.nextUpdate.Sub(before)
+
+		tolerance := time.Second
+
+		// the usual update can be up to 10% earlier or later than the cache duration
+		if test.expected == test.cacheDuration {
+			tolerance += test.cacheDuration / 10
+		}
+
+		if wait < test.expected-tolerance || wait > test.expected+tolerance {
+			t.Errorf("cache %s with error-cache %s after %d retries: expected the next update in %s, got %s", test.cacheDuration, test.errorCache, test.retries, test.expected, wait)
+		}
+	}
+}